@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+
+	"github.com/uacademy/e_commerce/api_gateway/metrics"
+	"github.com/uacademy/e_commerce/api_gateway/models"
+)
+
+// ErrQueueFull is returned by AsyncSink.Write when the entry was dropped
+var ErrQueueFull = errors.New("audit queue is full")
+
+// AsyncSink hands entries to a background worker, so that requests don't wait for a slow sink.
+// The queue is bounded: when it is full entries are dropped and counted rather than blocking requests.
+type AsyncSink struct {
+	sink  Sink
+	queue chan models.AuditEntry
+	done  chan struct{}
+}
+
+// NewAsyncSink starts a worker writing to sink with room for size pending entries
+func NewAsyncSink(sink Sink, size int) *AsyncSink {
+	s := &AsyncSink{
+		sink:  sink,
+		queue: make(chan models.AuditEntry, size),
+		done:  make(chan struct{}),
+	}
+
+	go s.run()
+
+	return s
+}
+
+// Write queues the entry, it must not be called after Close
+func (s *AsyncSink) Write(ctx context.Context, entry models.AuditEntry) error {
+	select {
+	case s.queue <- entry:
+		return nil
+	default:
+		metrics.AuditEntriesDroppedTotal.WithLabelValues(metrics.AuditQueueFull).Inc()
+		return ErrQueueFull
+	}
+}
+
+// Query reads entries back from the underlying sink, entries still queued are not included
+func (s *AsyncSink) Query(ctx context.Context, query models.AuditQuery) ([]models.AuditEntry, error) {
+	return Query(ctx, s.sink, query)
+}
+
+// Close writes the queued entries and closes the underlying sink if it can be closed
+func (s *AsyncSink) Close() error {
+	close(s.queue)
+	<-s.done
+
+	if closer, ok := s.sink.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (s *AsyncSink) run() {
+	defer close(s.done)
+
+	// the request which produced the entry may be long gone
+	ctx := context.Background()
+	for entry := range s.queue {
+		if err := s.sink.Write(ctx, entry); err != nil {
+			metrics.AuditEntriesDroppedTotal.WithLabelValues(metrics.AuditWriteFailed).Inc()
+			log.Printf("audit: failed to write entry: %v", err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/uacademy/e_commerce/api_gateway/config"
+	"github.com/uacademy/e_commerce/api_gateway/models"
+)
+
+// Outcomes
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Supported sinks
+const (
+	SinkFile   = "file"
+	SinkStdout = "stdout"
+	SinkHTTP   = "http"
+)
+
+// MaxQueryLimit bounds the number of entries a query returns, and so the memory it takes
+const MaxQueryLimit = 1000
+
+// ErrQueryNotSupported is returned by Query when the configured sink can't be read back
+var ErrQueryNotSupported = errors.New("audit sink does not support queries")
+
+// Sink persists audit entries
+type Sink interface {
+	Write(ctx context.Context, entry models.AuditEntry) error
+}
+
+// Querier is implemented by sinks which can read their entries back
+type Querier interface {
+	Query(ctx context.Context, query models.AuditQuery) ([]models.AuditEntry, error)
+}
+
+// NewSink builds the sink selected in config
+func NewSink(cfg config.Config) (Sink, error) {
+	switch cfg.AuditSink {
+	case SinkFile:
+		return NewFileSink(cfg.AuditFilePath)
+	case SinkStdout:
+		return NewStdoutSink(), nil
+	case SinkHTTP:
+		return NewHTTPSink(cfg.AuditCollectorURL), nil
+	}
+
+	return nil, fmt.Errorf("unknown audit sink %q", cfg.AuditSink)
+}
+
+// Query reads entries back from sink when it supports it
+func Query(ctx context.Context, sink Sink, query models.AuditQuery) ([]models.AuditEntry, error) {
+	querier, ok := sink.(Querier)
+	if !ok {
+		return nil, ErrQueryNotSupported
+	}
+
+	return querier.Query(ctx, query)
+}
+
+func matches(entry models.AuditEntry, query models.AuditQuery) bool {
+	if query.UserId != "" && entry.UserId != query.UserId {
+		return false
+	}
+	if query.Resource != "" && entry.Resource != query.Resource {
+		return false
+	}
+	if query.ResourceId != "" && entry.ResourceId != query.ResourceId {
+		return false
+	}
+	if !query.From.IsZero() && entry.Time.Before(query.From) {
+		return false
+	}
+	if !query.To.IsZero() && entry.Time.After(query.To) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,110 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/uacademy/e_commerce/api_gateway/models"
+)
+
+// FileSink appends entries as JSON lines to a file which is never rewritten
+type FileSink struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens (or creates) path in append-only mode
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSink{
+		path: path,
+		file: file,
+	}, nil
+}
+
+// Write ...
+func (s *FileSink) Write(ctx context.Context, entry models.AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	return s.file.Sync()
+}
+
+// Query scans the file and returns the newest matching entries first.
+// Only the last Limit matches, at most MaxQueryLimit, are kept while scanning.
+func (s *FileSink) Query(ctx context.Context, query models.AuditQuery) ([]models.AuditEntry, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	limit := query.Limit
+	if limit <= 0 || limit > MaxQueryLimit {
+		limit = MaxQueryLimit
+	}
+
+	// ring buffer, once full the oldest match is overwritten at next
+	ring := make([]models.AuditEntry, 0, limit)
+	next := 0
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var entry models.AuditEntry
+			if jsonErr := json.Unmarshal(line, &entry); jsonErr == nil && matches(entry, query) {
+				if len(ring) < limit {
+					ring = append(ring, entry)
+				} else {
+					ring[next] = entry
+				}
+				next = (next + 1) % limit
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	// newest first, walking back from the last match written
+	entries := make([]models.AuditEntry, len(ring))
+	for i := range entries {
+		entries[i] = ring[(next-1-i+2*len(ring))%len(ring)]
+	}
+
+	return entries, nil
+}
+
+// Close ...
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/uacademy/e_commerce/api_gateway/models"
+)
+
+// HTTPSink posts every entry as JSON to an external collector
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSink ...
+func NewHTTPSink(url string) *HTTPSink {
+	return &HTTPSink{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Write ...
+func (s *HTTPSink) Write(ctx context.Context, entry models.AuditEntry) error {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("audit collector responded with %s", resp.Status)
+	}
+
+	return nil
+}
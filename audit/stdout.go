@@ -0,0 +1,36 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/uacademy/e_commerce/api_gateway/models"
+)
+
+// StdoutSink writes entries as JSON lines to stdout, for log shippers to pick up
+type StdoutSink struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewStdoutSink ...
+func NewStdoutSink() *StdoutSink {
+	return &StdoutSink{out: os.Stdout}
+}
+
+// Write ...
+func (s *StdoutSink) Write(ctx context.Context, entry models.AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.out.Write(append(line, '\n'))
+	return err
+}
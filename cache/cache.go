@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+
+	"github.com/uacademy/e_commerce/api_gateway/config"
+)
+
+// Supported stores
+const (
+	// StoreMemory is only fit for a single gateway instance:
+	// writes handled by one instance don't invalidate the caches of the others
+	StoreMemory = "memory"
+	StoreRedis  = "redis"
+)
+
+// Entry is a cached HTTP response
+type Entry struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// Store keeps cached responses
+type Store interface {
+	Get(ctx context.Context, key string) (Entry, bool, error)
+	Set(ctx context.Context, key string, entry Entry, ttl time.Duration) error
+	// DeletePrefix drops every entry whose key starts with prefix
+	DeletePrefix(ctx context.Context, prefix string) error
+	// Generation returns the current generation of namespace, zero until it is first bumped
+	Generation(ctx context.Context, namespace string) (int64, error)
+	// Bump advances the generation of namespace
+	Bump(ctx context.Context, namespace string) error
+}
+
+// New builds the store selected in config
+func New(cfg config.Config, pool *redis.Pool) (Store, error) {
+	switch cfg.CacheStore {
+	case StoreMemory:
+		return NewLRUStore(cfg.CacheLRUSize), nil
+	case StoreRedis:
+		return NewRedisStore(pool), nil
+	}
+
+	return nil, fmt.Errorf("unknown cache store %q", cfg.CacheStore)
+}
@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+type lruItem struct {
+	key       string
+	entry     Entry
+	expiresAt time.Time
+}
+
+// LRUStore keeps up to size entries in process memory, evicting the least recently used.
+// It is not shared, so it must only be used by a single gateway instance.
+type LRUStore struct {
+	size int
+
+	mu          sync.Mutex
+	order       *list.List
+	items       map[string]*list.Element
+	generations map[string]int64
+}
+
+// NewLRUStore ...
+func NewLRUStore(size int) *LRUStore {
+	return &LRUStore{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+
+		generations: make(map[string]int64),
+	}
+}
+
+// Get ...
+func (s *LRUStore) Get(ctx context.Context, key string) (Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[key]
+	if !ok {
+		return Entry{}, false, nil
+	}
+
+	item := el.Value.(*lruItem)
+	if time.Now().After(item.expiresAt) {
+		s.remove(el)
+		return Entry{}, false, nil
+	}
+
+	s.order.MoveToFront(el)
+
+	return item.entry, true, nil
+}
+
+// Set ...
+func (s *LRUStore) Set(ctx context.Context, key string, entry Entry, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[key]; ok {
+		item := el.Value.(*lruItem)
+		item.entry = entry
+		item.expiresAt = time.Now().Add(ttl)
+		s.order.MoveToFront(el)
+		return nil
+	}
+
+	s.items[key] = s.order.PushFront(&lruItem{
+		key:       key,
+		entry:     entry,
+		expiresAt: time.Now().Add(ttl),
+	})
+
+	for s.order.Len() > s.size {
+		s.remove(s.order.Back())
+	}
+
+	return nil
+}
+
+// DeletePrefix ...
+func (s *LRUStore) DeletePrefix(ctx context.Context, prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, el := range s.items {
+		if strings.HasPrefix(key, prefix) {
+			s.remove(el)
+		}
+	}
+
+	return nil
+}
+
+// Generation ...
+func (s *LRUStore) Generation(ctx context.Context, namespace string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.generations[namespace], nil
+}
+
+// Bump ...
+func (s *LRUStore) Bump(ctx context.Context, namespace string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.generations[namespace]++
+	return nil
+}
+
+func (s *LRUStore) remove(el *list.Element) {
+	s.order.Remove(el)
+	delete(s.items, el.Value.(*lruItem).key)
+}
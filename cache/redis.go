@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// RedisStore keeps entries in a Redis-protocol server shared by all gateway instances
+type RedisStore struct {
+	pool   *redis.Pool
+	prefix string
+}
+
+// NewRedisStore ...
+func NewRedisStore(pool *redis.Pool) *RedisStore {
+	return &RedisStore{
+		pool:   pool,
+		prefix: "cache:",
+	}
+}
+
+// Get ...
+func (s *RedisStore) Get(ctx context.Context, key string) (Entry, bool, error) {
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return Entry{}, false, err
+	}
+	defer conn.Close()
+
+	b, err := redis.Bytes(conn.Do("GET", s.prefix+key))
+	if err == redis.ErrNil {
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return Entry{}, false, err
+	}
+
+	return entry, true, nil
+}
+
+// Set ...
+func (s *RedisStore) Set(ctx context.Context, key string, entry Entry, ttl time.Duration) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Do("SET", s.prefix+key, b, "PX", ttl.Milliseconds())
+	return err
+}
+
+// DeletePrefix ...
+func (s *RedisStore) DeletePrefix(ctx context.Context, prefix string) error {
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	cursor := 0
+	for {
+		values, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", s.prefix+prefix+"*", "COUNT", 100))
+		if err != nil {
+			return err
+		}
+
+		cursor, err = redis.Int(values[0], nil)
+		if err != nil {
+			return err
+		}
+
+		keys, err := redis.Strings(values[1], nil)
+		if err != nil {
+			return err
+		}
+
+		if len(keys) > 0 {
+			if _, err := conn.Do("DEL", redis.Args{}.AddFlat(keys)...); err != nil {
+				return err
+			}
+		}
+
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// Generation ...
+func (s *RedisStore) Generation(ctx context.Context, namespace string) (int64, error) {
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	generation, err := redis.Int64(conn.Do("GET", s.generationKey(namespace)))
+	if err == redis.ErrNil {
+		return 0, nil
+	}
+	return generation, err
+}
+
+// Bump ...
+func (s *RedisStore) Bump(ctx context.Context, namespace string) error {
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Do("INCR", s.generationKey(namespace))
+	return err
+}
+
+// generationKey is outside of the keys of namespace, so that DeletePrefix keeps it
+func (s *RedisStore) generationKey(namespace string) string {
+	return s.prefix + "generation:" + namespace
+}
@@ -0,0 +1,85 @@
+package cart
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+
+	"github.com/uacademy/e_commerce/api_gateway/config"
+)
+
+// Supported stores
+const (
+	StoreMemory = "memory"
+	StoreFile   = "file"
+	StoreRedis  = "redis"
+)
+
+// Item is a product in a cart, prices are looked up when the cart is read
+type Item struct {
+	ProductId string    `json:"product_id"`
+	Quantity  int32     `json:"quantity"`
+	AddedAt   time.Time `json:"added_at"`
+}
+
+// Cart belongs to a single user, items keep the order they were added in
+type Cart struct {
+	UserId    string    `json:"user_id"`
+	Items     []Item    `json:"items"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// CheckoutAt is set while an order of the cart is being placed, so that it isn't ordered twice
+	CheckoutAt *time.Time `json:"checkout_at,omitempty"`
+}
+
+// Find returns the index of the item for productId, -1 if the cart has none
+func (c *Cart) Find(productId string) int {
+	for i, item := range c.Items {
+		if item.ProductId == productId {
+			return i
+		}
+	}
+	return -1
+}
+
+// Clone returns a copy of c which doesn't share its items, so that callers can't change stored ones
+func (c Cart) Clone() Cart {
+	items := make([]Item, len(c.Items))
+	copy(items, c.Items)
+	c.Items = items
+	return c
+}
+
+// Store keeps carts by user
+type Store interface {
+	// Get returns the cart of userId, an empty one if there is none
+	Get(ctx context.Context, userId string) (Cart, error)
+	// Update applies fn to the cart of userId and saves the result, no other update of the cart runs in between.
+	// Nothing is saved when fn fails.
+	Update(ctx context.Context, userId string, fn func(cart *Cart) error) (Cart, error)
+	// Delete empties the cart of userId
+	Delete(ctx context.Context, userId string) error
+}
+
+// New builds the store selected in config
+func New(cfg config.Config, pool *redis.Pool) (Store, error) {
+	switch cfg.CartStore {
+	case StoreMemory:
+		return NewMemoryStore(cfg.CartTTL), nil
+	case StoreFile:
+		return NewFileStore(cfg.CartFilePath, cfg.CartTTL)
+	case StoreRedis:
+		return NewRedisStore(pool, cfg.CartTTL), nil
+	}
+
+	return nil, fmt.Errorf("unknown cart store %q", cfg.CartStore)
+}
+
+// current returns the cart unless it has been left untouched for longer than ttl
+func current(cart Cart, userId string, ttl time.Duration, now time.Time) Cart {
+	if cart.UserId == "" || now.Sub(cart.UpdatedAt) > ttl {
+		return Cart{UserId: userId, Items: []Item{}}
+	}
+	return cart
+}
@@ -0,0 +1,156 @@
+package cart
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStoreUpdate(t *testing.T) {
+	stores := []struct {
+		name string
+		new  func(t *testing.T, ttl time.Duration) Store
+	}{
+		{name: "memory", new: func(t *testing.T, ttl time.Duration) Store {
+			return NewMemoryStore(ttl)
+		}},
+		{name: "file", new: func(t *testing.T, ttl time.Duration) Store {
+			s, err := NewFileStore(filepath.Join(t.TempDir(), "carts.json"), ttl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		}},
+	}
+
+	add := func(productId string, quantity int32) func(cart *Cart) error {
+		return func(cart *Cart) error {
+			if i := cart.Find(productId); i >= 0 {
+				cart.Items[i].Quantity += quantity
+				return nil
+			}
+			cart.Items = append(cart.Items, Item{ProductId: productId, Quantity: quantity})
+			return nil
+		}
+	}
+
+	for _, st := range stores {
+		t.Run(st.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			t.Run("failed update saves nothing", func(t *testing.T) {
+				s := st.new(t, time.Hour)
+				if _, err := s.Update(ctx, "u1", add("p1", 1)); err != nil {
+					t.Fatal(err)
+				}
+
+				errStop := errors.New("stop")
+				_, err := s.Update(ctx, "u1", func(cart *Cart) error {
+					cart.Items[0].Quantity = 5
+					cart.Items = append(cart.Items, Item{ProductId: "p2", Quantity: 1})
+					return errStop
+				})
+				if !errors.Is(err, errStop) {
+					t.Fatalf("error = %v, want %v", err, errStop)
+				}
+
+				got, _ := s.Get(ctx, "u1")
+				if len(got.Items) != 1 || got.Items[0].Quantity != 1 {
+					t.Errorf("items = %+v, want p1 once", got.Items)
+				}
+			})
+
+			t.Run("concurrent updates are serialized", func(t *testing.T) {
+				s := st.new(t, time.Hour)
+
+				const n = 50
+				var wg sync.WaitGroup
+				for i := 0; i < n; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						if _, err := s.Update(ctx, "u1", add("p1", 1)); err != nil {
+							t.Error(err)
+						}
+					}()
+				}
+				wg.Wait()
+
+				got, _ := s.Get(ctx, "u1")
+				if len(got.Items) != 1 || got.Items[0].Quantity != n {
+					t.Errorf("items = %+v, want p1 %d times", got.Items, n)
+				}
+			})
+
+			t.Run("returned carts don't share stored items", func(t *testing.T) {
+				s := st.new(t, time.Hour)
+				updated, err := s.Update(ctx, "u1", add("p1", 1))
+				if err != nil {
+					t.Fatal(err)
+				}
+				updated.Items[0].Quantity = 7
+
+				read, _ := s.Get(ctx, "u1")
+				read.Items[0].Quantity = 8
+
+				if got, _ := s.Get(ctx, "u1"); got.Items[0].Quantity != 1 {
+					t.Errorf("quantity = %d, want 1", got.Items[0].Quantity)
+				}
+			})
+
+			t.Run("expired cart starts empty", func(t *testing.T) {
+				s := st.new(t, -time.Second)
+				if _, err := s.Update(ctx, "u1", add("p1", 1)); err != nil {
+					t.Fatal(err)
+				}
+
+				got, _ := s.Get(ctx, "u1")
+				if got.UserId != "u1" || got.Items == nil || len(got.Items) != 0 {
+					t.Errorf("cart = %+v, want an empty one of u1", got)
+				}
+			})
+
+			t.Run("delete empties the cart", func(t *testing.T) {
+				s := st.new(t, time.Hour)
+				if _, err := s.Update(ctx, "u1", add("p1", 1)); err != nil {
+					t.Fatal(err)
+				}
+				if err := s.Delete(ctx, "u1"); err != nil {
+					t.Fatal(err)
+				}
+
+				if got, _ := s.Get(ctx, "u1"); len(got.Items) != 0 {
+					t.Errorf("items = %+v, want none", got.Items)
+				}
+			})
+		})
+	}
+}
+
+func TestFileStoreReload(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "carts.json")
+
+	s, err := NewFileStore(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Update(ctx, "u1", func(cart *Cart) error {
+		cart.Items = append(cart.Items, Item{ProductId: "p1", Quantity: 2})
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewFileStore(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := reloaded.Get(ctx, "u1")
+	if len(got.Items) != 1 || got.Items[0].ProductId != "p1" || got.Items[0].Quantity != 2 {
+		t.Errorf("items = %+v, want p1 twice", got.Items)
+	}
+}
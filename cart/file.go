@@ -0,0 +1,132 @@
+package cart
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileStore keeps all carts in a single JSON file, which is rewritten on every change.
+// It suits a single gateway instance that must keep carts over restarts.
+type FileStore struct {
+	path string
+	ttl  time.Duration
+
+	mu    sync.Mutex
+	carts map[string]Cart
+}
+
+// NewFileStore loads the carts in path, which is created on the first change if it doesn't exist
+func NewFileStore(path string, ttl time.Duration) (*FileStore, error) {
+	s := &FileStore{
+		path:  path,
+		ttl:   ttl,
+		carts: make(map[string]Cart),
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, &s.carts); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Get ...
+func (s *FileStore) Get(ctx context.Context, userId string) (Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return current(s.carts[userId], userId, s.ttl, time.Now()).Clone(), nil
+}
+
+// Update ...
+func (s *FileStore) Update(ctx context.Context, userId string, fn func(cart *Cart) error) (Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	cart := current(s.carts[userId], userId, s.ttl, now).Clone()
+	if err := fn(&cart); err != nil {
+		return Cart{}, err
+	}
+	cart.UpdatedAt = now
+
+	previous, existed := s.carts[userId]
+	s.carts[userId] = cart
+	if err := s.flush(now); err != nil {
+		if existed {
+			s.carts[userId] = previous
+		} else {
+			delete(s.carts, userId)
+		}
+		return Cart{}, err
+	}
+
+	return cart.Clone(), nil
+}
+
+// Delete ...
+func (s *FileStore) Delete(ctx context.Context, userId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.carts[userId]
+	if !existed {
+		return nil
+	}
+
+	delete(s.carts, userId)
+	if err := s.flush(time.Now()); err != nil {
+		s.carts[userId] = previous
+		return err
+	}
+
+	return nil
+}
+
+// flush drops expired carts and replaces the file, writing to a temporary one first
+// so a crash never leaves it half written
+func (s *FileStore) flush(now time.Time) error {
+	for userId, cart := range s.carts {
+		if now.Sub(cart.UpdatedAt) > s.ttl {
+			delete(s.carts, userId)
+		}
+	}
+
+	b, err := json.Marshal(s.carts)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
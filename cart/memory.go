@@ -0,0 +1,74 @@
+package cart
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memorySweepInterval is how often MemoryStore drops expired carts, on the first update after it passed
+const memorySweepInterval = time.Minute
+
+// MemoryStore keeps carts in process memory, they are lost on restart and not shared between instances
+type MemoryStore struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	carts   map[string]Cart
+	sweptAt time.Time
+}
+
+// NewMemoryStore ...
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{
+		ttl:   ttl,
+		carts: make(map[string]Cart),
+	}
+}
+
+// Get ...
+func (s *MemoryStore) Get(ctx context.Context, userId string) (Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return current(s.carts[userId], userId, s.ttl, time.Now()).Clone(), nil
+}
+
+// Update ...
+func (s *MemoryStore) Update(ctx context.Context, userId string, fn func(cart *Cart) error) (Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.sweptAt) > memorySweepInterval {
+		s.sweep(now)
+	}
+
+	cart := current(s.carts[userId], userId, s.ttl, now).Clone()
+	if err := fn(&cart); err != nil {
+		return Cart{}, err
+	}
+	cart.UpdatedAt = now
+
+	s.carts[userId] = cart
+	return cart.Clone(), nil
+}
+
+// Delete ...
+func (s *MemoryStore) Delete(ctx context.Context, userId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.carts, userId)
+	return nil
+}
+
+// sweep drops expired carts, like FileStore.flush, which would otherwise stay in memory until the user comes back
+func (s *MemoryStore) sweep(now time.Time) {
+	for userId, cart := range s.carts {
+		if now.Sub(cart.UpdatedAt) > s.ttl {
+			delete(s.carts, userId)
+		}
+	}
+	s.sweptAt = now
+}
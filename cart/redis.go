@@ -0,0 +1,128 @@
+package cart
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// ErrConflict is returned when a cart kept changing under an update until it gave up
+var ErrConflict = errors.New("cart was modified concurrently")
+
+// updateAttempts bounds how often Update retries after the cart changed under it
+const updateAttempts = 5
+
+// RedisStore keeps carts in a Redis-protocol server shared by all gateway instances,
+// each cart expires ttl after its last change
+type RedisStore struct {
+	pool   *redis.Pool
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisStore ...
+func NewRedisStore(pool *redis.Pool, ttl time.Duration) *RedisStore {
+	return &RedisStore{
+		pool:   pool,
+		prefix: "cart:",
+		ttl:    ttl,
+	}
+}
+
+// Get ...
+func (s *RedisStore) Get(ctx context.Context, userId string) (Cart, error) {
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return Cart{}, err
+	}
+	defer conn.Close()
+
+	return s.get(conn, userId)
+}
+
+// Update watches the key of the cart, so the transaction is retried when another update wins the race
+func (s *RedisStore) Update(ctx context.Context, userId string, fn func(cart *Cart) error) (Cart, error) {
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return Cart{}, err
+	}
+	defer conn.Close()
+
+	key := s.prefix + userId
+
+	for attempt := 0; attempt < updateAttempts; attempt++ {
+		if _, err := conn.Do("WATCH", key); err != nil {
+			return Cart{}, err
+		}
+
+		cart, err := s.get(conn, userId)
+		if err != nil {
+			conn.Do("UNWATCH")
+			return Cart{}, err
+		}
+
+		if err := fn(&cart); err != nil {
+			conn.Do("UNWATCH")
+			return Cart{}, err
+		}
+		cart.UpdatedAt = time.Now()
+
+		b, err := json.Marshal(cart)
+		if err != nil {
+			conn.Do("UNWATCH")
+			return Cart{}, err
+		}
+
+		conn.Send("MULTI")
+		conn.Send("SET", key, b, "PX", s.ttl.Milliseconds())
+		reply, err := conn.Do("EXEC")
+		if err != nil {
+			return Cart{}, err
+		}
+		// a nil reply means the key changed after WATCH
+		if reply != nil {
+			return cart, nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			return Cart{}, err
+		}
+	}
+
+	return Cart{}, ErrConflict
+}
+
+// Delete ...
+func (s *RedisStore) Delete(ctx context.Context, userId string) error {
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Do("DEL", s.prefix+userId)
+	return err
+}
+
+func (s *RedisStore) get(conn redis.Conn, userId string) (Cart, error) {
+	b, err := redis.Bytes(conn.Do("GET", s.prefix+userId))
+	if err == redis.ErrNil {
+		return Cart{UserId: userId, Items: []Item{}}, nil
+	}
+	if err != nil {
+		return Cart{}, err
+	}
+
+	var cart Cart
+	if err := json.Unmarshal(b, &cart); err != nil {
+		return Cart{}, err
+	}
+	if cart.Items == nil {
+		cart.Items = []Item{}
+	}
+
+	return cart, nil
+}
@@ -0,0 +1,133 @@
+package clients
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/uacademy/e_commerce/api_gateway/metrics"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type breakerState int
+
+const (
+	stateClosed breakerState = iota
+	stateHalfOpen
+	stateOpen
+)
+
+// circuitBreakers keeps one breaker per backend service
+type circuitBreakers struct {
+	failureThreshold int
+	openTimeout      time.Duration
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+type breaker struct {
+	service  string
+	state    breakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newCircuitBreakers(failureThreshold int, openTimeout time.Duration) *circuitBreakers {
+	return &circuitBreakers{
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		breakers:         make(map[string]*breaker),
+	}
+}
+
+// UnaryClientInterceptor fails fast with codes.Unavailable while the breaker of the called service is open
+func (cb *circuitBreakers) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		service, _ := metrics.SplitMethodName(method)
+
+		if !cb.allow(service) {
+			return status.Errorf(codes.Unavailable, "circuit breaker is open for %s", service)
+		}
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		cb.record(service, isBackendFailure(err))
+
+		return err
+	}
+}
+
+func (cb *circuitBreakers) get(service string) *breaker {
+	b, ok := cb.breakers[service]
+	if !ok {
+		b = &breaker{service: service}
+		cb.breakers[service] = b
+		b.setState(stateClosed)
+	}
+	return b
+}
+
+func (cb *circuitBreakers) allow(service string) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	b := cb.get(service)
+	switch b.state {
+	case stateOpen:
+		if time.Since(b.openedAt) < cb.openTimeout {
+			return false
+		}
+		b.setState(stateHalfOpen)
+		b.probing = true
+		return true
+	case stateHalfOpen:
+		// only a single probe is let through until it reports back
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+func (cb *circuitBreakers) record(service string, failed bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	b := cb.get(service)
+	b.probing = false
+
+	if !failed {
+		b.failures = 0
+		if b.state != stateClosed {
+			b.setState(stateClosed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == stateHalfOpen || b.failures >= cb.failureThreshold {
+		b.openedAt = time.Now()
+		b.setState(stateOpen)
+	}
+}
+
+func (b *breaker) setState(state breakerState) {
+	b.state = state
+	metrics.CircuitBreakerState.WithLabelValues(b.service).Set(float64(state))
+}
+
+// isBackendFailure reports whether err means the backend itself is unhealthy,
+// as opposed to a regular business error such as not found or invalid argument
+func isBackendFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package clients
+
+import (
+	"context"
+	"time"
+
+	"github.com/uacademy/e_commerce/api_gateway/metrics"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// bulkhead caps the number of concurrent calls to one backend,
+// so that a slow backend can't tie up every request the gateway serves
+type bulkhead struct {
+	backend string
+	slots   chan struct{}
+	maxWait time.Duration
+}
+
+func newBulkhead(backend string, maxConcurrent int, maxWait time.Duration) *bulkhead {
+	return &bulkhead{
+		backend: backend,
+		slots:   make(chan struct{}, maxConcurrent),
+		maxWait: maxWait,
+	}
+}
+
+// UnaryClientInterceptor waits up to maxWait for a free slot and fails with codes.ResourceExhausted otherwise
+func (b *bulkhead) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := b.acquire(ctx); err != nil {
+			return err
+		}
+		defer b.release()
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func (b *bulkhead) acquire(ctx context.Context) error {
+	select {
+	case b.slots <- struct{}{}:
+		metrics.BulkheadInFlight.WithLabelValues(b.backend).Inc()
+		return nil
+	default:
+	}
+
+	timer := time.NewTimer(b.maxWait)
+	defer timer.Stop()
+
+	select {
+	case b.slots <- struct{}{}:
+		metrics.BulkheadInFlight.WithLabelValues(b.backend).Inc()
+		return nil
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	metrics.BulkheadRejectedTotal.WithLabelValues(b.backend).Inc()
+	return status.Errorf(codes.ResourceExhausted, "too many concurrent requests to %s", b.backend)
+}
+
+func (b *bulkhead) release() {
+	<-b.slots
+	metrics.BulkheadInFlight.WithLabelValues(b.backend).Dec()
+}
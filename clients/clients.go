@@ -2,7 +2,9 @@ package clients
 
 import (
 	"github.com/uacademy/e_commerce/api_gateway/config"
+	"github.com/uacademy/e_commerce/api_gateway/metrics"
 	ecom "github.com/uacademy/e_commerce/api_gateway/proto-gen/e_commerce"
+	"github.com/uacademy/e_commerce/api_gateway/tracing"
 
 	"google.golang.org/grpc"
 )
@@ -17,25 +19,52 @@ type GrpcClients struct {
 }
 
 func NewGrpcClients(cfg config.Config) (*GrpcClients, error) {
-	connCategory, err := grpc.Dial(cfg.CatalogServiceGrpcHost+cfg.CatalogServiceGrpcPort, grpc.WithInsecure())
+	breakers := newCircuitBreakers(cfg.CircuitBreakerFailureThreshold, cfg.CircuitBreakerOpenTimeout)
+	coalescer := newCoalescer(cfg.CoalesceTimeout)
+
+	// category and product are served by the same catalog backend and share its bulkhead
+	catalogBulkhead := newBulkhead("catalog", cfg.CatalogServiceMaxConcurrent, cfg.BulkheadMaxWait)
+	orderBulkhead := newBulkhead("order", cfg.OrderServiceMaxConcurrent, cfg.BulkheadMaxWait)
+	authBulkhead := newBulkhead("auth", cfg.AuthServiceMaxConcurrent, cfg.BulkheadMaxWait)
+
+	// coalesced calls are counted by metrics and take a bulkhead slot only once;
+	// bulkhead rejections happen before the breaker so they don't count as backend failures
+	opts := func(b *bulkhead) []grpc.DialOption {
+		interceptors := []grpc.UnaryClientInterceptor{tracing.UnaryClientInterceptor()}
+		if cfg.CoalesceEnabled {
+			interceptors = append(interceptors, coalescer.UnaryClientInterceptor())
+		}
+		interceptors = append(interceptors,
+			metrics.UnaryClientInterceptor(),
+			b.UnaryClientInterceptor(),
+			breakers.UnaryClientInterceptor(),
+		)
+
+		return []grpc.DialOption{
+			grpc.WithInsecure(),
+			grpc.WithChainUnaryInterceptor(interceptors...),
+		}
+	}
+
+	connCategory, err := grpc.Dial(cfg.CatalogServiceGrpcHost+cfg.CatalogServiceGrpcPort, opts(catalogBulkhead)...)
 	if err != nil {
 		return nil, err
 	}
 	category := ecom.NewCategoryServiceClient(connCategory)
 
-	connProduct, err := grpc.Dial(cfg.CatalogServiceGrpcHost+cfg.CatalogServiceGrpcPort, grpc.WithInsecure())
+	connProduct, err := grpc.Dial(cfg.CatalogServiceGrpcHost+cfg.CatalogServiceGrpcPort, opts(catalogBulkhead)...)
 	if err != nil {
 		return nil, err
 	}
 	product := ecom.NewProductServiceClient(connProduct)
 
-	connOrder, err := grpc.Dial(cfg.OrderServiceGrpcHost+cfg.OrderServiceGrpcPort, grpc.WithInsecure())
+	connOrder, err := grpc.Dial(cfg.OrderServiceGrpcHost+cfg.OrderServiceGrpcPort, opts(orderBulkhead)...)
 	if err != nil {
 		return nil, err
 	}
 	order := ecom.NewOrderServiceClient(connOrder)
 
-	connAuth, err := grpc.Dial(cfg.AuthServiceGrpcHost+cfg.AuthServiceGrpcPort, grpc.WithInsecure())
+	connAuth, err := grpc.Dial(cfg.AuthServiceGrpcHost+cfg.AuthServiceGrpcPort, opts(authBulkhead)...)
 	if err != nil {
 		return nil, err
 	}
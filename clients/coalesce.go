@@ -0,0 +1,92 @@
+package clients
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/uacademy/e_commerce/api_gateway/metrics"
+
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// coalescer merges identical in-flight calls to idempotent methods into a single backend call.
+// Every caller receives its own copy of the shared response.
+type coalescer struct {
+	group   singleflight.Group
+	timeout time.Duration
+}
+
+func newCoalescer(timeout time.Duration) *coalescer {
+	return &coalescer{timeout: timeout}
+}
+
+// UnaryClientInterceptor ...
+func (co *coalescer) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		service, name := metrics.SplitMethodName(method)
+		if !isIdempotent(name) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		in, inOk := req.(proto.Message)
+		out, outOk := reply.(proto.Message)
+		if !inOk || !outOk {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		b, err := proto.MarshalOptions{Deterministic: true}.Marshal(in)
+		if err != nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		metrics.GrpcClientCoalescibleTotal.WithLabelValues(service, name).Inc()
+
+		ch := co.group.DoChan(method+"\x00"+string(b), func() (interface{}, error) {
+			// the shared call must not fail because the caller who happened to start it went away
+			callCtx, cancel := context.WithTimeout(detach(ctx), co.timeout)
+			defer cancel()
+
+			shared := out.ProtoReflect().New().Interface()
+			err := invoker(callCtx, method, req, shared, cc, opts...)
+			return shared, err
+		})
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case res := <-ch:
+			if res.Shared {
+				metrics.GrpcClientCoalescedTotal.WithLabelValues(service, name).Inc()
+			}
+			if res.Err != nil {
+				return res.Err
+			}
+			proto.Merge(out, res.Val.(proto.Message))
+			return nil
+		}
+	}
+}
+
+// isIdempotent reports whether calls to method can safely be shared between callers
+func isIdempotent(method string) bool {
+	return strings.HasPrefix(method, "Get")
+}
+
+// detachedContext keeps the values (trace span, outgoing metadata) of its parent but not its cancellation
+type detachedContext struct {
+	context.Context
+}
+
+func detach(ctx context.Context) context.Context {
+	return detachedContext{ctx}
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detachedContext) Done() <-chan struct{} { return nil }
+
+func (detachedContext) Err() error { return nil }
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cast"
@@ -15,6 +16,7 @@ type Config struct {
 	Environment string //development, staging, production
 
 	HTTPPort    string
+	MetricsPort string //internal listener for /metrics, not to be exposed publicly
 
 	CatalogServiceGrpcHost string
 	CatalogServiceGrpcPort string
@@ -24,6 +26,79 @@ type Config struct {
 
 	AuthServiceGrpcHost string
 	AuthServiceGrpcPort string
+
+	CircuitBreakerFailureThreshold int
+	CircuitBreakerOpenTimeout      time.Duration
+
+	TracingExporter     string //none, stdout, otlp
+	TracingOTLPEndpoint string
+	TracingOTLPInsecure bool
+	TracingSampleRatio  float64
+
+	ErrorReportingDSN string
+
+	AuditSink         string //file, stdout, http
+	AuditFilePath     string
+	AuditCollectorURL string
+	AuditQueueSize    int
+
+	RateLimitStore      string //memory, redis
+	RateLimitMemorySize int
+	RateLimitDefault    string
+	RateLimitRoutes     string
+	RateLimitPreAuth    string
+
+	APIKeys        string //comma separated keys of API clients
+	TrustedProxies string //comma separated IPs or CIDRs allowed to set X-Forwarded-For
+
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+	RedisMaxIdle  int
+
+	CatalogServiceMaxConcurrent int
+	OrderServiceMaxConcurrent   int
+	AuthServiceMaxConcurrent    int
+	BulkheadMaxWait             time.Duration
+
+	LoadShedInitialLimit     int
+	LoadShedMinLimit         int
+	LoadShedMaxLimit         int
+	LoadShedBackoff          float64
+	LoadShedLatencyTarget    time.Duration
+	LoadShedLowPriorityShare float64
+
+	CoalesceEnabled bool
+	CoalesceTimeout time.Duration
+
+	CacheStore       string //memory (single instance only), redis
+	CacheLRUSize     int
+	CacheProductTTL  time.Duration
+	CacheCategoryTTL time.Duration
+
+	CursorSecret string
+	CursorTTL    time.Duration
+
+	DefaultPageSize   int
+	MaxPageSize       int
+	StrictQueryParams bool
+
+	ExpandMaxDepth        int
+	ExpandConcurrency     int
+	ExpandBatchRetryAfter time.Duration
+
+	JSONNaming string //snake_case, camelCase
+
+	BaseCurrency  string
+	ExchangeRates string
+
+	LegacyRoutesDeprecation time.Time
+	LegacyRoutesSunset      time.Time
+
+	CartStore    string //memory, file, redis
+	CartFilePath string
+	CartTTL      time.Duration
+	CartMaxItems int
 }
 
 // Load ...
@@ -39,6 +114,7 @@ func Load() Config {
 	config.Environment = cast.ToString(getOrReturnDefaultValue("ENVIRONMENT", "development"))
 
 	config.HTTPPort = cast.ToString(getOrReturnDefaultValue("HTTP_PORT", ":7071"))
+	config.MetricsPort = cast.ToString(getOrReturnDefaultValue("METRICS_PORT", "localhost:9100"))
 
 	config.CatalogServiceGrpcHost = cast.ToString(getOrReturnDefaultValue("CATALOG_SERVICE_GRPC_HOST", "localhost"))
 	config.CatalogServiceGrpcPort = cast.ToString(getOrReturnDefaultValue("CATALOG_SERVICE_GRPC_PORT", ":9001"))
@@ -49,6 +125,79 @@ func Load() Config {
 	config.AuthServiceGrpcHost = cast.ToString(getOrReturnDefaultValue("AUTH_SERVICE_GRPC_HOST", "localhost"))
 	config.AuthServiceGrpcHost = cast.ToString(getOrReturnDefaultValue("AUTH_SERVICE_GRPC_PORT", ":9003"))
 
+	config.CircuitBreakerFailureThreshold = cast.ToInt(getOrReturnDefaultValue("CIRCUIT_BREAKER_FAILURE_THRESHOLD", 5))
+	config.CircuitBreakerOpenTimeout = cast.ToDuration(getOrReturnDefaultValue("CIRCUIT_BREAKER_OPEN_TIMEOUT", "30s"))
+
+	config.TracingExporter = cast.ToString(getOrReturnDefaultValue("TRACING_EXPORTER", "none"))
+	config.TracingOTLPEndpoint = cast.ToString(getOrReturnDefaultValue("TRACING_OTLP_ENDPOINT", "localhost:4317"))
+	config.TracingOTLPInsecure = cast.ToBool(getOrReturnDefaultValue("TRACING_OTLP_INSECURE", true))
+	config.TracingSampleRatio = cast.ToFloat64(getOrReturnDefaultValue("TRACING_SAMPLE_RATIO", 1.0))
+
+	config.ErrorReportingDSN = cast.ToString(getOrReturnDefaultValue("ERROR_REPORTING_DSN", ""))
+
+	config.AuditSink = cast.ToString(getOrReturnDefaultValue("AUDIT_SINK", "file"))
+	config.AuditFilePath = cast.ToString(getOrReturnDefaultValue("AUDIT_FILE_PATH", "audit.log"))
+	config.AuditCollectorURL = cast.ToString(getOrReturnDefaultValue("AUDIT_COLLECTOR_URL", ""))
+	config.AuditQueueSize = cast.ToInt(getOrReturnDefaultValue("AUDIT_QUEUE_SIZE", 1000))
+
+	config.RateLimitStore = cast.ToString(getOrReturnDefaultValue("RATE_LIMIT_STORE", "memory"))
+	config.RateLimitMemorySize = cast.ToInt(getOrReturnDefaultValue("RATE_LIMIT_MEMORY_SIZE", 100000))
+	config.RateLimitDefault = cast.ToString(getOrReturnDefaultValue("RATE_LIMIT_DEFAULT", "100/m"))
+	config.RateLimitRoutes = cast.ToString(getOrReturnDefaultValue("RATE_LIMIT_ROUTES", "POST /v1/login=5/m,POST /v1/order=10/m"))
+	config.RateLimitPreAuth = cast.ToString(getOrReturnDefaultValue("RATE_LIMIT_PRE_AUTH", "300/m"))
+
+	config.APIKeys = cast.ToString(getOrReturnDefaultValue("API_KEYS", ""))
+	config.TrustedProxies = cast.ToString(getOrReturnDefaultValue("TRUSTED_PROXIES", ""))
+
+	config.RedisAddr = cast.ToString(getOrReturnDefaultValue("REDIS_ADDR", "localhost:6379"))
+	config.RedisPassword = cast.ToString(getOrReturnDefaultValue("REDIS_PASSWORD", ""))
+	config.RedisDB = cast.ToInt(getOrReturnDefaultValue("REDIS_DB", 0))
+	config.RedisMaxIdle = cast.ToInt(getOrReturnDefaultValue("REDIS_MAX_IDLE", 10))
+
+	config.CatalogServiceMaxConcurrent = cast.ToInt(getOrReturnDefaultValue("CATALOG_SERVICE_MAX_CONCURRENT", 100))
+	config.OrderServiceMaxConcurrent = cast.ToInt(getOrReturnDefaultValue("ORDER_SERVICE_MAX_CONCURRENT", 50))
+	config.AuthServiceMaxConcurrent = cast.ToInt(getOrReturnDefaultValue("AUTH_SERVICE_MAX_CONCURRENT", 100))
+	config.BulkheadMaxWait = cast.ToDuration(getOrReturnDefaultValue("BULKHEAD_MAX_WAIT", "50ms"))
+
+	config.LoadShedInitialLimit = cast.ToInt(getOrReturnDefaultValue("LOAD_SHED_INITIAL_LIMIT", 100))
+	config.LoadShedMinLimit = cast.ToInt(getOrReturnDefaultValue("LOAD_SHED_MIN_LIMIT", 10))
+	config.LoadShedMaxLimit = cast.ToInt(getOrReturnDefaultValue("LOAD_SHED_MAX_LIMIT", 1000))
+	config.LoadShedBackoff = cast.ToFloat64(getOrReturnDefaultValue("LOAD_SHED_BACKOFF", 0.9))
+	config.LoadShedLatencyTarget = cast.ToDuration(getOrReturnDefaultValue("LOAD_SHED_LATENCY_TARGET", "500ms"))
+	config.LoadShedLowPriorityShare = cast.ToFloat64(getOrReturnDefaultValue("LOAD_SHED_LOW_PRIORITY_SHARE", 0.8))
+
+	config.CoalesceEnabled = cast.ToBool(getOrReturnDefaultValue("COALESCE_ENABLED", true))
+	config.CoalesceTimeout = cast.ToDuration(getOrReturnDefaultValue("COALESCE_TIMEOUT", "10s"))
+
+	config.CacheStore = cast.ToString(getOrReturnDefaultValue("CACHE_STORE", "memory"))
+	config.CacheLRUSize = cast.ToInt(getOrReturnDefaultValue("CACHE_LRU_SIZE", 1000))
+	config.CacheProductTTL = cast.ToDuration(getOrReturnDefaultValue("CACHE_PRODUCT_TTL", "30s"))
+	config.CacheCategoryTTL = cast.ToDuration(getOrReturnDefaultValue("CACHE_CATEGORY_TTL", "5m"))
+
+	config.CursorSecret = cast.ToString(getOrReturnDefaultValue("CURSOR_SECRET", ""))
+	config.CursorTTL = cast.ToDuration(getOrReturnDefaultValue("CURSOR_TTL", "24h"))
+
+	config.DefaultPageSize = cast.ToInt(getOrReturnDefaultValue("DEFAULT_PAGE_SIZE", 10))
+	config.MaxPageSize = cast.ToInt(getOrReturnDefaultValue("MAX_PAGE_SIZE", 100))
+	config.StrictQueryParams = cast.ToBool(getOrReturnDefaultValue("STRICT_QUERY_PARAMS", false))
+
+	config.ExpandMaxDepth = cast.ToInt(getOrReturnDefaultValue("EXPAND_MAX_DEPTH", 2))
+	config.ExpandConcurrency = cast.ToInt(getOrReturnDefaultValue("EXPAND_CONCURRENCY", 8))
+	config.ExpandBatchRetryAfter = cast.ToDuration(getOrReturnDefaultValue("EXPAND_BATCH_RETRY_AFTER", "5m"))
+
+	config.JSONNaming = cast.ToString(getOrReturnDefaultValue("JSON_NAMING", "snake_case"))
+
+	config.BaseCurrency = cast.ToString(getOrReturnDefaultValue("BASE_CURRENCY", "USD"))
+	config.ExchangeRates = cast.ToString(getOrReturnDefaultValue("EXCHANGE_RATES", "USD=1"))
+
+	config.LegacyRoutesDeprecation = cast.ToTime(getOrReturnDefaultValue("LEGACY_ROUTES_DEPRECATION", "2026-11-01"))
+	config.LegacyRoutesSunset = cast.ToTime(getOrReturnDefaultValue("LEGACY_ROUTES_SUNSET", "2027-05-01"))
+
+	config.CartStore = cast.ToString(getOrReturnDefaultValue("CART_STORE", "memory"))
+	config.CartFilePath = cast.ToString(getOrReturnDefaultValue("CART_FILE_PATH", "carts.json"))
+	config.CartTTL = cast.ToDuration(getOrReturnDefaultValue("CART_TTL", "720h"))
+	config.CartMaxItems = cast.ToInt(getOrReturnDefaultValue("CART_MAX_ITEMS", 50))
+
 	return config
 }
 
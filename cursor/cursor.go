@@ -0,0 +1,118 @@
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalid is returned for cursors which are malformed or were not issued by this gateway
+	ErrInvalid = errors.New("invalid cursor")
+	// ErrExpired is returned for genuine cursors which are older than the signer's ttl
+	ErrExpired = errors.New("cursor has expired")
+)
+
+// Cursor points right after the last item of a page in a keyset-paginated listing
+type Cursor struct {
+	// Resource is the listing the cursor was issued for, such as product
+	Resource string `json:"r"`
+	// Filters is the FiltersHash of the listing's filters, the cursor is only valid for the same ones
+	Filters string `json:"f,omitempty"`
+	// Sort is the ordering the cursor was issued for, it is only valid for the same one
+	Sort    string `json:"s,omitempty"`
+	SortKey string `json:"k"`
+	LastId  string `json:"i"`
+	// Expires is set by Encode, in unix seconds
+	Expires int64 `json:"e"`
+}
+
+// FiltersHash returns a digest of the filters of a listing which doesn't depend on the order
+// they were given in, empty filters are left out
+func FiltersHash(filters map[string][]string) string {
+	names := make([]string, 0, len(filters))
+	for name, values := range filters {
+		if len(values) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	hash := sha256.New()
+	for _, name := range names {
+		values := append([]string(nil), filters[name]...)
+		sort.Strings(values)
+
+		hash.Write([]byte(name))
+		for _, value := range values {
+			hash.Write([]byte("\x00" + value))
+		}
+		hash.Write([]byte("\x01"))
+	}
+
+	return base64.RawURLEncoding.EncodeToString(hash.Sum(nil)[:12])
+}
+
+// Signer issues and verifies opaque cursor tokens.
+// A token is the base64url JSON payload and its HMAC-SHA256, so clients can't forge or edit them.
+type Signer struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewSigner returns a signer whose cursors are valid for ttl
+func NewSigner(secret string, ttl time.Duration) *Signer {
+	return &Signer{secret: []byte(secret), ttl: ttl, now: time.Now}
+}
+
+// Encode ...
+func (s *Signer) Encode(c Cursor) string {
+	c.Expires = s.now().Add(s.ttl).Unix()
+	payload, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(s.sign(payload))
+}
+
+// Decode verifies token and returns the cursor it carries
+func (s *Signer) Decode(token string) (Cursor, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return Cursor{}, ErrInvalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return Cursor{}, ErrInvalid
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return Cursor{}, ErrInvalid
+	}
+
+	if !hmac.Equal(signature, s.sign(payload)) {
+		return Cursor{}, ErrInvalid
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(payload, &c); err != nil || c.LastId == "" {
+		return Cursor{}, ErrInvalid
+	}
+	if s.now().Unix() >= c.Expires {
+		return Cursor{}, ErrExpired
+	}
+
+	return c, nil
+}
+
+func (s *Signer) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,129 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignerRoundTrip(t *testing.T) {
+	s := NewSigner("secret", time.Hour)
+	want := Cursor{Resource: "product", Filters: "f", Sort: "-price", SortKey: "100", LastId: "p1"}
+
+	got, err := s.Decode(s.Encode(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want.Expires = got.Expires
+	if got != want {
+		t.Errorf("decoded %+v, want %+v", got, want)
+	}
+	if until := time.Until(time.Unix(got.Expires, 0)); until <= 0 || until > time.Hour {
+		t.Errorf("expires in %v, want within the ttl", until)
+	}
+}
+
+func TestSignerRejectsTampering(t *testing.T) {
+	s := NewSigner("secret", time.Hour)
+	token := s.Encode(Cursor{Resource: "product", SortKey: "100", LastId: "p1"})
+	payload, signature, _ := strings.Cut(token, ".")
+
+	forged, _ := base64.RawURLEncoding.DecodeString(payload)
+	forged = []byte(strings.Replace(string(forged), `"p1"`, `"p2"`, 1))
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "empty", token: ""},
+		{name: "no signature", token: payload},
+		{name: "extra part", token: token + ".x"},
+		{name: "payload not base64", token: "!!." + signature},
+		{name: "signature not base64", token: payload + ".!!"},
+		{name: "edited payload", token: base64.RawURLEncoding.EncodeToString(forged) + "." + signature},
+		{name: "truncated signature", token: token[:len(token)-2]},
+		{name: "other secret", token: NewSigner("other", time.Hour).Encode(Cursor{Resource: "product", LastId: "p1"})},
+		{name: "no last id", token: s.Encode(Cursor{Resource: "product"})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Decode(tt.token); !errors.Is(err, ErrInvalid) {
+				t.Errorf("error = %v, want ErrInvalid", err)
+			}
+		})
+	}
+}
+
+func TestSignerExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := NewSigner("secret", time.Minute)
+	s.now = func() time.Time { return now }
+
+	token := s.Encode(Cursor{Resource: "product", LastId: "p1"})
+
+	tests := []struct {
+		elapsed time.Duration
+		err     error
+	}{
+		{elapsed: 0},
+		{elapsed: 59 * time.Second},
+		{elapsed: time.Minute, err: ErrExpired},
+		{elapsed: time.Hour, err: ErrExpired},
+	}
+
+	for _, tt := range tests {
+		s.now = func() time.Time { return now.Add(tt.elapsed) }
+		if _, err := s.Decode(token); err != tt.err {
+			t.Errorf("after %v error = %v, want %v", tt.elapsed, err, tt.err)
+		}
+	}
+}
+
+func TestFiltersHash(t *testing.T) {
+	base := FiltersHash(map[string][]string{
+		"search": {"phone"},
+		"status": {"paid", "pending"},
+	})
+
+	tests := []struct {
+		name    string
+		filters map[string][]string
+		same    bool
+	}{
+		{name: "values in another order", same: true, filters: map[string][]string{
+			"status": {"pending", "paid"},
+			"search": {"phone"},
+		}},
+		{name: "empty filters left out", same: true, filters: map[string][]string{
+			"search":     {"phone"},
+			"status":     {"paid", "pending"},
+			"product_id": nil,
+		}},
+		{name: "other value", filters: map[string][]string{
+			"search": {"case"},
+			"status": {"paid", "pending"},
+		}},
+		{name: "fewer values", filters: map[string][]string{
+			"search": {"phone"},
+			"status": {"paid"},
+		}},
+		{name: "value moved to another filter", filters: map[string][]string{
+			"search":     {"phone"},
+			"status":     {"paid"},
+			"product_id": {"pending"},
+		}},
+		{name: "no filters", filters: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FiltersHash(tt.filters); (got == base) != tt.same {
+				t.Errorf("hash equal = %v, want %v", got == base, tt.same)
+			}
+		})
+	}
+}
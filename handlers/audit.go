@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/uacademy/e_commerce/api_gateway/audit"
+	"github.com/uacademy/e_commerce/api_gateway/models"
+)
+
+// ListAuditEntries godoc
+// @Summary     List audit entries
+// @Description get recorded create/update/delete operations, newest first
+// @Tags        audit
+// @Accept      json
+// @Produce     json
+// @Param       user_id       query    string false "User ID"
+// @Param       resource      query    string false "product"
+// @Param       resource_id   query    string false "Resource ID"
+// @Param       from          query    string false "2006-01-02T15:04:05Z"
+// @Param       to            query    string false "2006-01-02T15:04:05Z"
+// @Param       limit         query    int    false "100, at most 1000"
+// @Param       Authorization header   string false "Authorization"
+// @Success     200           {object} models.JSONResult{data=[]models.AuditEntry}
+// @Failure     400           {object} models.JSONError
+// @Failure     500           {object} models.JSONError
+// @Failure     501           {object} models.JSONError
+// @Router      /v1/audit [get]
+func (h Handler) GetAuditEntryList(c *gin.Context) {
+	query := models.AuditQuery{Limit: 100}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, models.JSONError{Error: err.Error()})
+		return
+	}
+
+	entries, err := audit.Query(c.Request.Context(), h.AuditSink, query)
+	if errors.Is(err, audit.ErrQueryNotSupported) {
+		c.JSON(http.StatusNotImplemented, models.JSONError{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.JSONError{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.JSONResult{
+		Message: "OK",
+		Data:    entries,
+	})
+}
+
+// auditBefore records the state of a resource a request is about to change for its audit entry,
+// the state after the change is taken from the response
+func (h Handler) auditBefore(c *gin.Context, resource interface{}) {
+	c.Set(models.AuditBeforeKey, h.jsonValue(c, resource))
+}
+
+// auditDeleted records a deleted resource, which is in the response but no longer exists
+func (h Handler) auditDeleted(c *gin.Context, resource interface{}) {
+	c.Set(models.AuditBeforeKey, h.jsonValue(c, resource))
+	c.Set(models.AuditAfterKey, nil)
+}
+
+// auditChange records both states of a resource whose response doesn't render it as stored
+func (h Handler) auditChange(c *gin.Context, before, after interface{}) {
+	c.Set(models.AuditBeforeKey, h.jsonValue(c, before))
+	c.Set(models.AuditAfterKey, h.jsonValue(c, after))
+}
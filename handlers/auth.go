@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/uacademy/e_commerce/api_gateway/metrics"
 	"github.com/uacademy/e_commerce/api_gateway/models"
 	ecom "github.com/uacademy/e_commerce/api_gateway/proto-gen/e_commerce"
 
@@ -17,7 +18,8 @@ func (h Handler) AuthMiddleware(userType string) gin.HandlerFunc {
 			Token: token,
 		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, models.JSONError{
+			metrics.AuthDecisionsTotal.WithLabelValues(metrics.AuthError, userType).Inc()
+			c.JSON(grpcStatus(err, http.StatusInternalServerError), models.JSONError{
 				Error: err.Error(),
 			})
 			c.Abort()
@@ -25,6 +27,7 @@ func (h Handler) AuthMiddleware(userType string) gin.HandlerFunc {
 		}
 
 		if !hasAccessResponse.HasAccess {
+			metrics.AuthDecisionsTotal.WithLabelValues(metrics.AuthUnauthorized, userType).Inc()
 			c.JSON(http.StatusUnauthorized, "Unauthorized")
 			c.Abort()
 			return
@@ -32,11 +35,15 @@ func (h Handler) AuthMiddleware(userType string) gin.HandlerFunc {
 
 		if userType != "*" {
 			if hasAccessResponse.User.UserType != userType {
+				metrics.AuthDecisionsTotal.WithLabelValues(metrics.AuthForbidden, userType).Inc()
 				c.JSON(http.StatusUnauthorized, "Permission Denied")
 				c.Abort()
+				return
 			}
 		}
 
+		metrics.AuthDecisionsTotal.WithLabelValues(metrics.AuthAllowed, userType).Inc()
+		c.Set(models.AuthUserKey, hasAccessResponse.User)
 		c.Next()
 		//
 	}
@@ -54,18 +61,16 @@ func (h Handler) AuthMiddleware(userType string) gin.HandlerFunc {
 // @Router      /v1/login [post]
 func (h Handler) Login(c *gin.Context) {
 	var body models.LoginModel
-	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{Error: err.Error()})
+	if !bindJSON(c, &body) {
 		return
 	}
 
-	// TODO - validation should be here
 	tokenResponse, err := h.GrpcClients.Auth.Login(c.Request.Context(), &ecom.LoginRequest{
 		Username: body.Username,
 		Password: body.Password,
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusInternalServerError), models.JSONError{
 			Error: err.Error(),
 		})
 		return
@@ -73,6 +78,6 @@ func (h Handler) Login(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, models.JSONResult{
 		Message: "OK",
-		Data:    tokenResponse,
+		Data:    h.jsonValue(c, tokenResponse),
 	})
 }
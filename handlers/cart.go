@@ -0,0 +1,506 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/uacademy/e_commerce/api_gateway/cart"
+	"github.com/uacademy/e_commerce/api_gateway/models"
+	"github.com/uacademy/e_commerce/api_gateway/money"
+	ecom "github.com/uacademy/e_commerce/api_gateway/proto-gen/e_commerce"
+)
+
+// maxCartQuantity is the most of one product a cart holds, the same as an order line takes
+const maxCartQuantity = 1000
+
+// checkoutTimeout is how long a checkout holds the cart, after it another one may start
+// in case the instance placing the order died before releasing it
+const checkoutTimeout = time.Minute
+
+var (
+	// errCartRejected stops a cart update which the request isn't allowed to make, the reason is reported separately
+	errCartRejected = errors.New("cart update rejected")
+	// errCheckoutInProgress stops a checkout of a cart another request is checking out
+	errCheckoutInProgress = errors.New("checkout in progress")
+)
+
+// GetCart godoc
+// @Summary     Get cart
+// @Description get the cart of the authenticated user, priced at the current prices of its products
+// @Tags        cart
+// @Accept      json
+// @Produce     json
+// @Param       currency      query    string false "EUR"
+// @Param       Authorization header   string false "Authorization"
+// @Success     200           {object} models.JSONResult{data=models.Cart}
+// @Failure     401           {object} models.JSONError
+// @Failure     500           {object} models.JSONError
+// @Router      /v1/cart [get]
+func (h Handler) GetCart(c *gin.Context) {
+	userId, ok := cartUser(c)
+	if !ok {
+		return
+	}
+
+	userCart, err := h.Carts.Get(c.Request.Context(), userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.JSONError{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	h.cartResponse(c, http.StatusOK, userCart)
+}
+
+// AddCartItem godoc
+// @Summary     Add cart item
+// @Description add a product to the cart of the authenticated user, the quantity is added to the one already in the cart
+// @Tags        cart
+// @Accept      json
+// @Produce     json
+// @Param       item          body     models.AddCartItemModel true  "Cart item body"
+// @Param       Authorization header   string                  false "Authorization"
+// @Success     200           {object} models.JSONResult{data=models.Cart}
+// @Failure     400           {object} models.JSONError
+// @Failure     401           {object} models.JSONError
+// @Failure     500           {object} models.JSONError
+// @Router      /v1/cart/items [post]
+func (h Handler) AddCartItem(c *gin.Context) {
+	userId, ok := cartUser(c)
+	if !ok {
+		return
+	}
+
+	var body models.AddCartItemModel
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	found, err := fetchProducts(h, c.Request.Context(), []string{body.Product_id})
+	if err != nil {
+		c.JSON(grpcStatus(err, http.StatusInternalServerError), models.JSONError{
+			Error: err.Error(),
+		})
+		return
+	}
+	product, _ := found[body.Product_id].(*ecom.Product)
+	if product == nil {
+		validationErrorResponse(c, "invalid_body", []models.FieldError{
+			fieldError(c, "product_id", "not_found"),
+		})
+		return
+	}
+	if product.GetPrice() == nil {
+		validationErrorResponse(c, "invalid_body", []models.FieldError{
+			fieldError(c, "product_id", "not_for_sale"),
+		})
+		return
+	}
+
+	var (
+		rejection models.FieldError
+		before    cart.Cart
+	)
+	userCart, err := h.Carts.Update(c.Request.Context(), userId, func(userCart *cart.Cart) error {
+		before = userCart.Clone()
+		i := userCart.Find(body.Product_id)
+		if i < 0 {
+			if len(userCart.Items) >= h.Cfg.CartMaxItems {
+				rejection = fieldError(c, "product_id", "cart_full", h.Cfg.CartMaxItems)
+				return errCartRejected
+			}
+			userCart.Items = append(userCart.Items, cart.Item{
+				ProductId: body.Product_id,
+				Quantity:  body.Quantity,
+				AddedAt:   time.Now(),
+			})
+			return nil
+		}
+
+		quantity := userCart.Items[i].Quantity + body.Quantity
+		if quantity > maxCartQuantity {
+			rejection = fieldError(c, "quantity", "cart_quantity", quantity, maxCartQuantity)
+			return errCartRejected
+		}
+		userCart.Items[i].Quantity = quantity
+		return nil
+	})
+	if errors.Is(err, errCartRejected) {
+		validationErrorResponse(c, "invalid_body", []models.FieldError{rejection})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.JSONError{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	h.auditChange(c, before, userCart)
+	h.cartResponse(c, http.StatusOK, userCart)
+}
+
+// UpdateCartItem godoc
+// @Summary     Update cart item
+// @Description set the quantity of a product in the cart of the authenticated user
+// @Tags        cart
+// @Accept      json
+// @Produce     json
+// @Param       product_id    path     string                     true  "Product ID"
+// @Param       item          body     models.UpdateCartItemModel true  "Cart item body"
+// @Param       Authorization header   string                     false "Authorization"
+// @Success     200           {object} models.JSONResult{data=models.Cart}
+// @Failure     400           {object} models.JSONError
+// @Failure     401           {object} models.JSONError
+// @Failure     404           {object} models.JSONError
+// @Failure     500           {object} models.JSONError
+// @Router      /v1/cart/items/{product_id} [put]
+func (h Handler) UpdateCartItem(c *gin.Context) {
+	userId, ok := cartUser(c)
+	if !ok {
+		return
+	}
+
+	var body models.UpdateCartItemModel
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	var before cart.Cart
+	productId := c.Param("product_id")
+	userCart, err := h.Carts.Update(c.Request.Context(), userId, func(userCart *cart.Cart) error {
+		before = userCart.Clone()
+		i := userCart.Find(productId)
+		if i < 0 {
+			return errCartRejected
+		}
+		userCart.Items[i].Quantity = body.Quantity
+		return nil
+	})
+	if !cartUpdated(c, productId, err) {
+		return
+	}
+
+	h.auditChange(c, before, userCart)
+	h.cartResponse(c, http.StatusOK, userCart)
+}
+
+// RemoveCartItem godoc
+// @Summary     Remove cart item
+// @Description remove a product from the cart of the authenticated user
+// @Tags        cart
+// @Accept      json
+// @Produce     json
+// @Param       product_id    path     string true  "Product ID"
+// @Param       Authorization header   string false "Authorization"
+// @Success     200           {object} models.JSONResult{data=models.Cart}
+// @Failure     401           {object} models.JSONError
+// @Failure     404           {object} models.JSONError
+// @Failure     500           {object} models.JSONError
+// @Router      /v1/cart/items/{product_id} [delete]
+func (h Handler) RemoveCartItem(c *gin.Context) {
+	userId, ok := cartUser(c)
+	if !ok {
+		return
+	}
+
+	var before cart.Cart
+	productId := c.Param("product_id")
+	userCart, err := h.Carts.Update(c.Request.Context(), userId, func(userCart *cart.Cart) error {
+		before = userCart.Clone()
+		i := userCart.Find(productId)
+		if i < 0 {
+			return errCartRejected
+		}
+		userCart.Items = append(userCart.Items[:i], userCart.Items[i+1:]...)
+		return nil
+	})
+	if !cartUpdated(c, productId, err) {
+		return
+	}
+
+	h.auditChange(c, before, userCart)
+	h.cartResponse(c, http.StatusOK, userCart)
+}
+
+// ClearCart godoc
+// @Summary     Clear cart
+// @Description remove all products from the cart of the authenticated user
+// @Tags        cart
+// @Accept      json
+// @Produce     json
+// @Param       Authorization header   string false "Authorization"
+// @Success     200           {object} models.JSONResult{data=models.Cart}
+// @Failure     401           {object} models.JSONError
+// @Failure     409           {object} models.JSONError
+// @Failure     500           {object} models.JSONError
+// @Router      /v1/cart [delete]
+func (h Handler) ClearCart(c *gin.Context) {
+	userId, ok := cartUser(c)
+	if !ok {
+		return
+	}
+
+	// a cart being checked out is about to be ordered, clearing it would lose track of that
+	var before cart.Cart
+	emptied, err := h.Carts.Update(c.Request.Context(), userId, func(current *cart.Cart) error {
+		before = current.Clone()
+		if checkoutInProgress(current, time.Now()) {
+			return errCheckoutInProgress
+		}
+		current.Items = []cart.Item{}
+		return nil
+	})
+	if errors.Is(err, errCheckoutInProgress) {
+		c.JSON(http.StatusConflict, models.JSONError{
+			Error: localize(c, "checkout_in_progress"),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.JSONError{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	h.auditChange(c, before, emptied)
+	h.cartResponse(c, http.StatusOK, emptied)
+}
+
+// CheckoutCart godoc
+// @Summary     Checkout cart
+// @Description create an order of the products in the cart of the authenticated user, priced at their current prices.
+// @Description The ordered products are removed from the cart.
+// @Tags        cart
+// @Accept      json
+// @Produce     json
+// @Param       checkout      body     models.CheckoutCartModel true  "Checkout body"
+// @Param       Authorization header   string                   false "Authorization"
+// @Success     201           {object} models.JSONResult{data=models.Order}
+// @Failure     400           {object} models.JSONError
+// @Failure     401           {object} models.JSONError
+// @Failure     409           {object} models.JSONError
+// @Failure     500           {object} models.JSONError
+// @Router      /v1/cart/checkout [post]
+func (h Handler) CheckoutCart(c *gin.Context) {
+	userId, ok := cartUser(c)
+	if !ok {
+		return
+	}
+
+	var body models.CheckoutCartModel
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	// the cart is reserved before the order is placed, so that concurrent checkouts can't order it twice
+	userCart, err := h.Carts.Update(c.Request.Context(), userId, func(current *cart.Cart) error {
+		now := time.Now()
+		if checkoutInProgress(current, now) {
+			return errCheckoutInProgress
+		}
+		if len(current.Items) == 0 {
+			return errCartRejected
+		}
+		current.CheckoutAt = &now
+		return nil
+	})
+	if errors.Is(err, errCheckoutInProgress) {
+		c.JSON(http.StatusConflict, models.JSONError{
+			Error: localize(c, "checkout_in_progress"),
+		})
+		return
+	}
+	if errors.Is(err, errCartRejected) {
+		c.JSON(http.StatusBadRequest, models.JSONError{
+			Error: localize(c, "empty_cart"),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.JSONError{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	items := make([]models.CreateOrderItemModel, len(userCart.Items))
+	for i, item := range userCart.Items {
+		items[i] = models.CreateOrderItemModel{Product_id: item.ProductId, Quantity: item.Quantity}
+	}
+
+	order, ok := h.placeOrder(c, &ecom.CreateOrderRequest{
+		UserName:    body.User_name,
+		UserAddress: body.User_address,
+		UserPhone:   body.User_phone,
+	}, items, false)
+	if !ok {
+		h.releaseCart(userId)
+		return
+	}
+
+	// only what was ordered leaves the cart, products added meanwhile stay
+	_, err = h.Carts.Update(c.Request.Context(), userId, func(current *cart.Cart) error {
+		current.CheckoutAt = nil
+		for _, ordered := range userCart.Items {
+			i := current.Find(ordered.ProductId)
+			if i < 0 {
+				continue
+			}
+			if current.Items[i].Quantity > ordered.Quantity {
+				current.Items[i].Quantity -= ordered.Quantity
+				continue
+			}
+			current.Items = append(current.Items[:i], current.Items[i+1:]...)
+		}
+		return nil
+	})
+	if err != nil {
+		// the order exists, so the checkout succeeded regardless
+		log.Printf("cart: failed to remove ordered items: %v", err)
+	}
+
+	c.JSON(http.StatusCreated, models.JSONResult{
+		Message: "OK",
+		Data:    h.jsonValue(c, order),
+	})
+}
+
+// releaseCart lets the cart of userId be checked out again after a checkout failed
+func (h Handler) releaseCart(userId string) {
+	// the request may have been cancelled, which is why the checkout failed
+	_, err := h.Carts.Update(context.Background(), userId, func(current *cart.Cart) error {
+		current.CheckoutAt = nil
+		return nil
+	})
+	if err != nil {
+		log.Printf("cart: failed to release cart after a failed checkout: %v", err)
+	}
+}
+
+// cartUser returns the id of the user AuthMiddleware authenticated; on failure the response is already written
+func cartUser(c *gin.Context) (string, bool) {
+	if v, ok := c.Get(models.AuthUserKey); ok {
+		if user, ok := v.(*ecom.User); ok && user != nil && user.Id != "" {
+			return user.Id, true
+		}
+	}
+
+	c.JSON(http.StatusUnauthorized, models.JSONError{
+		Error: "Unauthorized",
+	})
+	return "", false
+}
+
+// cartUpdated reports whether an update of the item for productId went through; otherwise the response is written,
+// errCartRejected meaning the cart has no such item
+func cartUpdated(c *gin.Context, productId string, err error) bool {
+	if errors.Is(err, errCartRejected) {
+		c.JSON(http.StatusNotFound, models.JSONError{
+			Error: localize(c, "not_in_cart", productId),
+		})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.JSONError{
+			Error: err.Error(),
+		})
+		return false
+	}
+
+	return true
+}
+
+// cartResponse writes userCart priced at the current prices of its products.
+// Items whose product is gone or has no price are marked unavailable and left out of the total, which is in the base currency.
+func (h Handler) cartResponse(c *gin.Context, code int, userCart cart.Cart) {
+	ids := make([]string, len(userCart.Items))
+	for i, item := range userCart.Items {
+		ids[i] = item.ProductId
+	}
+
+	var found map[string]proto.Message
+	if len(ids) > 0 {
+		var err error
+		found, err = fetchProducts(h, c.Request.Context(), ids)
+		if err != nil {
+			c.JSON(grpcStatus(err, http.StatusInternalServerError), models.JSONError{
+				Error: err.Error(),
+			})
+			return
+		}
+	}
+
+	total := &ecom.Money{Currency: h.Cfg.BaseCurrency}
+	items := make([]interface{}, len(userCart.Items))
+
+	for i, item := range userCart.Items {
+		view := map[string]interface{}{
+			"product_id": item.ProductId,
+			"quantity":   item.Quantity,
+			"title":      "",
+			"available":  false,
+			"unit_price": nil,
+			"line_total": nil,
+			"added_at":   item.AddedAt.Format(time.RFC3339),
+		}
+		items[i] = view
+
+		product, _ := found[item.ProductId].(*ecom.Product)
+		if product == nil {
+			continue
+		}
+		view["title"] = product.Title
+
+		price := product.GetPrice()
+		if price == nil {
+			continue
+		}
+		view["unit_price"] = price
+
+		lineTotal, err := money.Multiply(price.Units, int64(item.Quantity))
+		if err != nil {
+			continue
+		}
+		converted, err := h.Rates.Convert(lineTotal, price.Currency, total.Currency)
+		if err != nil {
+			continue
+		}
+		sum, err := money.Add(total.Units, converted)
+		if err != nil {
+			continue
+		}
+
+		total.Units = sum
+		view["line_total"] = &ecom.Money{Units: lineTotal, Currency: price.Currency}
+		view["available"] = true
+	}
+
+	updatedAt := ""
+	if !userCart.UpdatedAt.IsZero() {
+		updatedAt = userCart.UpdatedAt.Format(time.RFC3339)
+	}
+
+	c.JSON(code, models.JSONResult{
+		Message: "OK",
+		Data: h.jsonValue(c, map[string]interface{}{
+			"user_id":    userCart.UserId,
+			"items":      items,
+			"total":      total,
+			"updated_at": updatedAt,
+		}),
+	})
+}
+
+// checkoutInProgress reports whether the cart is reserved by a checkout which hasn't timed out
+func checkoutInProgress(userCart *cart.Cart, now time.Time) bool {
+	return userCart.CheckoutAt != nil && now.Sub(*userCart.CheckoutAt) < checkoutTimeout
+}
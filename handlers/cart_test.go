@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/uacademy/e_commerce/api_gateway/cart"
+	"github.com/uacademy/e_commerce/api_gateway/models"
+	ecom "github.com/uacademy/e_commerce/api_gateway/proto-gen/e_commerce"
+)
+
+func TestCheckoutInProgress(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(-d)
+		return &t
+	}
+
+	tests := []struct {
+		name       string
+		checkoutAt *time.Time
+		want       bool
+	}{
+		{name: "not checked out", want: false},
+		{name: "just started", checkoutAt: at(0), want: true},
+		{name: "shortly before the timeout", checkoutAt: at(checkoutTimeout - time.Second), want: true},
+		{name: "at the timeout", checkoutAt: at(checkoutTimeout), want: false},
+		{name: "abandoned long ago", checkoutAt: at(time.Hour), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userCart := &cart.Cart{UserId: "u1", CheckoutAt: tt.checkoutAt}
+			if got := checkoutInProgress(userCart, now); got != tt.want {
+				t.Errorf("checkoutInProgress = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClearCartDuringCheckout(t *testing.T) {
+	ctx := context.Background()
+	store := cart.NewMemoryStore(time.Hour)
+	if _, err := store.Update(ctx, "u1", func(userCart *cart.Cart) error {
+		now := time.Now()
+		userCart.Items = append(userCart.Items, cart.Item{ProductId: "p1", Quantity: 1})
+		userCart.CheckoutAt = &now
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	c, w := newTestContext("/v1/cart")
+	c.Request.Method = http.MethodDelete
+	c.Set(models.AuthUserKey, &ecom.User{Id: "u1"})
+
+	h := Handler{Carts: store}
+	h.ClearCart(c)
+
+	var body models.JSONError
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusConflict || body.Error != "the cart is already being checked out" {
+		t.Errorf("response = %d %q, want %d", w.Code, body.Error, http.StatusConflict)
+	}
+
+	if got, _ := store.Get(ctx, "u1"); len(got.Items) != 1 {
+		t.Errorf("items = %+v, want the cart left as it was", got.Items)
+	}
+}
@@ -2,9 +2,9 @@ package handlers
 
 import (
 	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 
 	"net/http"
-	"strconv"
 
 	"github.com/uacademy/e_commerce/api_gateway/models"
 	ecom "github.com/uacademy/e_commerce/api_gateway/proto-gen/e_commerce"
@@ -24,8 +24,7 @@ import (
 // @Router      /v1/category [post]
 func (h Handler) CreateCategory(c *gin.Context) {
 	var body models.CreateCategoryModel
-	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{Error: err.Error()})
+	if !bindJSON(c, &body) {
 		return
 	}
 
@@ -33,7 +32,7 @@ func (h Handler) CreateCategory(c *gin.Context) {
 		CategoryTitle: body.CategoryTitle,
 	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusBadRequest), models.JSONError{
 			Error: err.Error(),
 		})
 		return
@@ -41,7 +40,7 @@ func (h Handler) CreateCategory(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, models.JSONResult{
 		Message: "OK",
-		Data:    category,
+		Data:    h.jsonValue(c, category),
 	})
 }
 
@@ -52,26 +51,36 @@ func (h Handler) CreateCategory(c *gin.Context) {
 // @Accept      json
 // @Produce     json
 // @Param       id            path     string true  "Category ID"
+// @Param       fields        query    string false "id,category_title"
 // @Param       Authorization header   string false "Authorization"
 // @Success     200           {object} models.JSONResult{data=models.Category}
+// @Failure     400           {object} models.JSONError
 // @Failure     404           {object} models.JSONError
 // @Router      /v1/category/{id} [get]
 func (h Handler) GetCategoryById(c *gin.Context) {
 	id := c.Param("id")
 
+	mask, ok := fieldMask(c, &ecom.GetCategoryByIdResponse{})
+	if !ok {
+		return
+	}
+
 	category, err := h.GrpcClients.Category.GetCategoryById(c.Request.Context(), &ecom.GetCategoryByIdRequest{
-		Id: id,
+		Id:       id,
+		ReadMask: mask,
 	})
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusNotFound), models.JSONError{
 			Error: err.Error(),
 		})
 		return
 	}
 
+	setResourceETag(c, category)
+
 	c.JSON(http.StatusOK, models.JSONResult{
 		Message: "OK",
-		Data:    category,
+		Data:    h.jsonValue(c, projected(mask, category)),
 	})
 }
 
@@ -81,86 +90,193 @@ func (h Handler) GetCategoryById(c *gin.Context) {
 // @Tags        categories
 // @Accept      json
 // @Produce     json
-// @Param       offset        query    string false "0"
-// @Param       limit         query    string false "10"
+// @Param       offset        query    int    false "0"
+// @Param       limit         query    int    false "10"
 // @Param       search        query    string false "smth"
+// @Param       fields        query    string false "id,category_title"
 // @Param       Authorization header   string false "Authorization"
-// @Success     200           {object} models.JSONResult{data=[]models.Category}
+// @Success     200           {object} models.JSONListResult{data=[]models.Category}
 // @Failure     400           {object} models.JSONError
 // @Failure     500           {object} models.JSONError
 // @Router      /v1/category [get]
 func (h Handler) GetCategoryList(c *gin.Context) {
-	offsetStr := c.DefaultQuery("offset", "0")
-	limitStr := c.DefaultQuery("limit", "10")
-	searchStr := c.DefaultQuery("search", "")
+	var query models.ListQuery
+	if !h.bindListQuery(c, &query) {
+		return
+	}
 
-	offset, err := strconv.Atoi(offsetStr)
+	mask, ok := fieldMask(c, &ecom.Category{})
+	if !ok {
+		return
+	}
+
+	categoryList, err := h.GrpcClients.Category.GetCategoryList(c.Request.Context(), &ecom.GetCategoryListRequest{
+		Offset:   int32(query.Offset),
+		Limit:    int32(query.Limit),
+		Search:   query.Search,
+		ReadMask: mask,
+	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusInternalServerError), models.JSONError{
 			Error: err.Error(),
 		})
 		return
 	}
 
-	limit, err := strconv.Atoi(limitStr)
+	data := make([]interface{}, len(categoryList.Categories))
+	for i, category := range categoryList.Categories {
+		data[i] = projected(mask, category)
+	}
+
+	h.listResponse(c, data, page{
+		total:  int(categoryList.Count),
+		offset: query.Offset,
+		limit:  query.Limit,
+	})
+}
+
+// UpdateCategory godoc
+// @Summary     Update category
+// @Description update category, the id in the body may be left out
+// @Tags        categories
+// @Accept      json
+// @Produce     json
+// @Param       id            path     string                     true  "Category ID"
+// @Param       category      body     models.UpdateCategoryModel true  "Category body"
+// @Param       Authorization header   string                     false "Authorization"
+// @Param       If-Match      header   string                     false "ETag of the category"
+// @Success     200           {object} models.JSONResult{data=models.Category}
+// @Failure     400           {object} models.JSONError
+// @Failure     404           {object} models.JSONError
+// @Failure     412           {object} models.JSONError
+// @Router      /v1/category/{id} [put]
+func (h Handler) UpdateCategory(c *gin.Context) {
+	body := models.UpdateCategoryModel{Id: c.Param("id")}
+	if !bindJSON(c, &body) || !checkPathId(c, body.Id) {
+		return
+	}
+
+	current, err := h.GrpcClients.Category.GetCategoryById(c.Request.Context(), &ecom.GetCategoryByIdRequest{
+		Id: body.Id,
+	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusNotFound), models.JSONError{
 			Error: err.Error(),
 		})
 		return
 	}
+	h.auditBefore(c, current)
 
-	categoryList, err := h.GrpcClients.Category.GetCategoryList(c.Request.Context(), &ecom.GetCategoryListRequest{
-		Offset: int32(offset),
-		Limit:  int32(limit),
-		Search: searchStr,
+	expectedVersion, ok := checkIfMatch(c, current)
+	if !ok {
+		return
+	}
+
+	category, err := h.GrpcClients.Category.UpdateCategory(c.Request.Context(), &ecom.UpdateCategoryRequest{
+		Id:              body.Id,
+		CategoryTitle:   body.CategoryTitle,
+		ExpectedVersion: expectedVersion,
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.JSONError{
-			Error: err.Error(),
-		})
+		updateError(c, err, expectedVersion)
 		return
 	}
 
+	c.Header("ETag", resourceETag(category))
+
 	c.JSON(http.StatusOK, models.JSONResult{
 		Message: "OK",
-		Data:    categoryList,
+		Data:    h.jsonValue(c, category),
 	})
 }
 
-// UpdateCategory godoc
+// UpdateCategoryLegacy godoc
 // @Summary     Update category
-// @Description update category
+// @Description update category with the id in the body, replaced by PUT /v1/category/{id}
 // @Tags        categories
 // @Accept      json
 // @Produce     json
 // @Param       category      body     models.UpdateCategoryModel true  "Category body"
 // @Param       Authorization header   string                     false "Authorization"
+// @Param       If-Match      header   string                     false "ETag of the category"
 // @Success     200           {object} models.JSONResult{data=models.Category}
 // @Failure     400           {object} models.JSONError
 // @Failure     404           {object} models.JSONError
+// @Failure     412           {object} models.JSONError
+// @Deprecated
 // @Router      /v1/category [put]
-func (h Handler) UpdateCategory(c *gin.Context) {
+func (h Handler) UpdateCategoryLegacy(c *gin.Context) {
+	h.UpdateCategory(c)
+}
+
+// PatchCategory godoc
+// @Summary     Patch category
+// @Description change some fields of a category, the body is a JSON merge patch (RFC 7396)
+// @Tags        categories
+// @Accept      application/merge-patch+json
+// @Produce     json
+// @Param       id            path     string                     true  "Category ID"
+// @Param       category      body     models.UpdateCategoryModel true  "Fields to change"
+// @Param       Authorization header   string                     false "Authorization"
+// @Param       If-Match      header   string                     false "ETag of the category"
+// @Success     200           {object} models.JSONResult{data=models.Category}
+// @Failure     400           {object} models.JSONError
+// @Failure     404           {object} models.JSONError
+// @Failure     412           {object} models.JSONError
+// @Failure     415           {object} models.JSONError
+// @Router      /v1/category/{id} [patch]
+func (h Handler) PatchCategory(c *gin.Context) {
+	id := c.Param("id")
+
+	current, err := h.GrpcClients.Category.GetCategoryById(c.Request.Context(), &ecom.GetCategoryByIdRequest{
+		Id: id,
+	})
+	if err != nil {
+		c.JSON(grpcStatus(err, http.StatusNotFound), models.JSONError{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	h.auditBefore(c, current)
+
+	expectedVersion, ok := checkIfMatch(c, current)
+	if !ok {
+		return
+	}
+
 	var body models.UpdateCategoryModel
-	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{Error: err.Error()})
+	paths, ok := bindMergePatch(c, current, &body)
+	if !ok {
+		return
+	}
+
+	// an empty patch changes nothing
+	if len(paths) == 0 {
+		c.Header("ETag", resourceETag(current))
+		c.JSON(http.StatusOK, models.JSONResult{
+			Message: "OK",
+			Data:    h.jsonValue(c, current),
+		})
 		return
 	}
 
 	category, err := h.GrpcClients.Category.UpdateCategory(c.Request.Context(), &ecom.UpdateCategoryRequest{
-		Id:            body.Id,
-		CategoryTitle: body.CategoryTitle,
+		Id:              id,
+		CategoryTitle:   body.CategoryTitle,
+		UpdateMask:      &fieldmaskpb.FieldMask{Paths: paths},
+		ExpectedVersion: expectedVersion,
 	})
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.JSONError{
-			Error: err.Error(),
-		})
+		updateError(c, err, expectedVersion)
 		return
 	}
 
+	c.Header("ETag", resourceETag(category))
+
 	c.JSON(http.StatusOK, models.JSONResult{
 		Message: "OK",
-		Data:    category,
+		Data:    h.jsonValue(c, category),
 	})
 }
 
@@ -182,14 +298,16 @@ func (h Handler) DeleteCategory(c *gin.Context) {
 		Id: id,
 	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusBadRequest), models.JSONError{
 			Error: err.Error(),
 		})
 		return
 	}
 
+	h.auditDeleted(c, category)
+
 	c.JSON(http.StatusOK, models.JSONResult{
 		Message: "OK",
-		Data:    category,
+		Data:    h.jsonValue(c, category),
 	})
 }
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcStatus picks the HTTP status for an error returned by a backend call.
+// Overloaded or unreachable backends are reported as 503 so that clients retry later,
+// any other error keeps the status the handler uses for it.
+func grpcStatus(err error, fallback int) int {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	}
+	return fallback
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/uacademy/e_commerce/api_gateway/middlewares"
+	"github.com/uacademy/e_commerce/api_gateway/models"
+)
+
+// versioned is implemented by the protos of resources which support conditional requests
+type versioned interface {
+	GetId() string
+	GetVersion() int64
+	GetCreatedAt() string
+	GetUpdatedAt() string
+}
+
+// resourceETag derives a strong ETag from the state of a resource rather than from its representation,
+// so that If-Match can be checked against the backend without rendering a response.
+// Resources embedded into the representation, such as the category of a product, are part of that state.
+// The version changes with every update; timestamps are only a fallback for backends without one,
+// as two updates within their precision get the same ETag.
+func resourceETag(r versioned, embedded ...versioned) string {
+	hash := sha256.New()
+	for _, v := range append([]versioned{r}, embedded...) {
+		hash.Write([]byte(v.GetId() + "\x00" + stateVersion(v) + "\x00"))
+	}
+
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+func stateVersion(r versioned) string {
+	if r.GetVersion() != 0 {
+		return strconv.FormatInt(r.GetVersion(), 10)
+	}
+	if r.GetUpdatedAt() != "" {
+		return r.GetUpdatedAt()
+	}
+	return r.GetCreatedAt()
+}
+
+// setResourceETag tags the plain representation of a resource with the resources embedded into it.
+// Responses shaped by query parameters fall back to the body hash of the ETag middleware.
+func setResourceETag(c *gin.Context, r versioned, embedded ...versioned) {
+	if c.Request.URL.RawQuery == "" {
+		c.Header("ETag", resourceETag(r, embedded...))
+	}
+}
+
+// checkIfMatch aborts with 412 Precondition Failed when the request carries an If-Match
+// header that doesn't match the current ETag of the resource, computed with the same embedded resources as on reads.
+// Otherwise it returns the version the update must be conditioned on, zero when there is no If-Match.
+func checkIfMatch(c *gin.Context, current versioned, embedded ...versioned) (int64, bool) {
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		return 0, true
+	}
+
+	if !middlewares.ETagMatches(ifMatch, resourceETag(current, embedded...), false) {
+		preconditionFailed(c)
+		return 0, false
+	}
+
+	return current.GetVersion(), true
+}
+
+// updateError responds to a failed update. The backend rejects an update conditioned on expectedVersion
+// with FAILED_PRECONDITION when another one got in first since If-Match was checked.
+func updateError(c *gin.Context, err error, expectedVersion int64) {
+	if expectedVersion != 0 && status.Code(err) == codes.FailedPrecondition {
+		preconditionFailed(c)
+		return
+	}
+
+	c.JSON(grpcStatus(err, http.StatusNotFound), models.JSONError{
+		Error: err.Error(),
+	})
+}
+
+func preconditionFailed(c *gin.Context) {
+	c.JSON(http.StatusPreconditionFailed, models.JSONError{
+		Error: "resource has been modified",
+	})
+}
@@ -0,0 +1,427 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"github.com/uacademy/e_commerce/api_gateway/models"
+	ecom "github.com/uacademy/e_commerce/api_gateway/proto-gen/e_commerce"
+)
+
+// relation is a resource that ?expand= can embed into another one
+type relation struct {
+	// idKeys are the keys of the parent which may hold the id of the related resource, in order of preference
+	idKeys []string
+	// itemsKey, if set, is a list of the parent whose elements hold ids under idKeys too,
+	// the related resource is embedded into each of them as well
+	itemsKey string
+	// resource is the kind of the related resource, it decides which relations can be expanded below it
+	resource string
+	fetch    func(h Handler, ctx context.Context, id string) (proto.Message, error)
+	// fetchMany, if set, resolves several ids at once; ids that don't exist are left out of the result
+	fetchMany func(h Handler, ctx context.Context, ids []string) (map[string]proto.Message, error)
+}
+
+// relations lists what can be expanded from each kind of resource
+var relations = map[string]map[string]relation{
+	"order": {
+		"product": {idKeys: []string{"product_id", "product.id"}, itemsKey: "items", resource: "product", fetch: fetchProduct, fetchMany: fetchProducts},
+	},
+	"product": {
+		"category": {idKeys: []string{"category_id", "category.id"}, resource: "category", fetch: fetchCategory},
+	},
+}
+
+// fetchProduct resolves a product in the shape of list items, the category is only embedded when expanded too
+func fetchProduct(h Handler, ctx context.Context, id string) (proto.Message, error) {
+	product, err := h.GrpcClients.Product.GetProductById(ctx, &ecom.GetProductByIdRequest{
+		Id: id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return productOf(product), nil
+}
+
+// productOf flattens a single product response into the shape of list items
+func productOf(product *ecom.GetProductByIdResponse) *ecom.Product {
+	return &ecom.Product{
+		Id:         product.Id,
+		CategoryId: product.GetCategory().GetId(),
+		Title:      product.Title,
+		Descrip:    product.Descrip,
+		Price:      product.Price,
+		CreatedAt:  product.CreatedAt,
+		UpdatedAt:  product.UpdatedAt,
+		Version:    product.Version,
+	}
+}
+
+// productsByIdsRetryAt is set, in unix nanoseconds, when the catalog service answered GetProductsByIds
+// with Unimplemented. Until then products are fetched one by one, after it the batch call is tried again
+// in case the service has been upgraded meanwhile.
+var productsByIdsRetryAt int64
+
+// fetchProducts resolves products with a single batch call where the catalog service supports it
+func fetchProducts(h Handler, ctx context.Context, ids []string) (map[string]proto.Message, error) {
+	if time.Now().UnixNano() >= atomic.LoadInt64(&productsByIdsRetryAt) {
+		resp, err := h.GrpcClients.Product.GetProductsByIds(ctx, &ecom.GetProductsByIdsRequest{
+			Ids: ids,
+		})
+		if err == nil {
+			found := make(map[string]proto.Message, len(resp.Products))
+			for _, product := range resp.Products {
+				found[product.Id] = product
+			}
+			return found, nil
+		}
+		if status.Code(err) != codes.Unimplemented {
+			return nil, err
+		}
+
+		atomic.StoreInt64(&productsByIdsRetryAt, time.Now().Add(h.Cfg.ExpandBatchRetryAfter).UnixNano())
+	}
+
+	return h.fetchEach(ctx, fetchProduct, ids)
+}
+
+func fetchCategory(h Handler, ctx context.Context, id string) (proto.Message, error) {
+	return h.GrpcClients.Category.GetCategoryById(ctx, &ecom.GetCategoryByIdRequest{
+		Id: id,
+	})
+}
+
+// parseExpand reads the expand query parameter, e.g. expand=product,product.category, for a resource.
+// defaults apply when the parameter is absent, an empty one expands nothing.
+// Paths are checked against relations and the configured depth limit; on failure the response is already written.
+func (h Handler) parseExpand(c *gin.Context, resource string, defaults ...string) (paths []string, ok bool) {
+	raw, present := c.GetQuery("expand")
+	if !present {
+		return defaults, true
+	}
+
+	var fields []models.FieldError
+	seen := make(map[string]bool)
+
+	for _, path := range strings.Split(raw, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" || seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		names := strings.Split(path, ".")
+		if len(names) > h.Cfg.ExpandMaxDepth {
+			fields = append(fields, fieldError(c, "expand", "too_deep", path, h.Cfg.ExpandMaxDepth))
+			continue
+		}
+
+		kind := resource
+		for _, name := range names {
+			rel, ok := relations[kind][name]
+			if !ok {
+				fields = append(fields, fieldError(c, "expand", "not_expandable", name, kind))
+				break
+			}
+			kind = rel.resource
+		}
+	}
+
+	if len(fields) > 0 {
+		validationErrorResponse(c, "invalid_query", fields)
+		return nil, false
+	}
+
+	for path := range seen {
+		paths = append(paths, path)
+	}
+
+	return paths, true
+}
+
+// render prepares items of a resource for a response: they are rendered with protojson,
+// the relations in expand are embedded and the result is projected with mask.
+// Related ids are taken from the items as returned by the backend. Relations the mask doesn't select
+// aren't fetched, embedded ones are projected with the paths below their name, e.g. product.title.
+func (h Handler) render(ctx context.Context, resource string, mask *fieldmaskpb.FieldMask, expand []string, items ...proto.Message) ([]interface{}, error) {
+	rendered := make([]map[string]interface{}, len(items))
+	for i, item := range items {
+		rendered[i] = toMap(item)
+	}
+
+	if mask != nil {
+		expand = selectedPaths(resource, expand, mask.GetPaths())
+	}
+
+	embedded, err := h.resolve(ctx, resource, rendered, expandTree(expand))
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]interface{}, len(items))
+	for i, item := range rendered {
+		for name, value := range embedded[i] {
+			item[name] = value
+		}
+		// items sharing a related resource share its map too, projecting it again with the same paths changes nothing
+		if mask != nil {
+			projectMap(item, mask.GetPaths())
+		}
+		data[i] = item
+	}
+
+	return data, nil
+}
+
+// readMask is the mask sent to the backend for m of a resource: the paths of mask that m has,
+// plus the ids the relations in expand it selects are resolved by, whether it selects the ids or not.
+// Paths selecting relations m doesn't have are left out.
+func readMask(m proto.Message, resource string, mask *fieldmaskpb.FieldMask, expand []string) *fieldmaskpb.FieldMask {
+	if mask == nil {
+		return nil
+	}
+
+	read := &fieldmaskpb.FieldMask{}
+	for _, path := range mask.GetPaths() {
+		// fails without appending for a path m doesn't have
+		_ = read.Append(m, path)
+	}
+
+	for name := range expandTree(selectedPaths(resource, expand, mask.GetPaths())) {
+		rel := relations[resource][name]
+		for _, key := range rel.idKeys {
+			if read.Append(m, key) == nil {
+				break
+			}
+		}
+		if rel.itemsKey != "" {
+			// elements are read whole, a mask can't select fields of them
+			_ = read.Append(m, rel.itemsKey)
+		}
+	}
+
+	read.Normalize()
+	return read
+}
+
+// selectedPaths keeps the expand paths of a resource whose relation, or the list it is embedded into, is selected by the mask paths
+func selectedPaths(resource string, expand, mask []string) []string {
+	selected := make(map[string]bool, len(mask))
+	for _, path := range mask {
+		name, _, _ := strings.Cut(path, ".")
+		selected[name] = true
+	}
+
+	var paths []string
+	for _, path := range expand {
+		name, _, _ := strings.Cut(path, ".")
+		if selected[name] || selected[relations[resource][name].itemsKey] {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// resolve fetches the relations in tree for every item, each distinct id once and all relations of a level in parallel.
+// The result holds the resources to embed into each item by relation name, nil where one no longer exists.
+func (h Handler) resolve(ctx context.Context, resource string, items []map[string]interface{}, tree map[string][]string) ([]map[string]interface{}, error) {
+	embedded := make([]map[string]interface{}, len(items))
+	for i := range embedded {
+		embedded[i] = make(map[string]interface{})
+	}
+	if len(tree) == 0 {
+		return embedded, nil
+	}
+
+	type resolved struct {
+		rel     relation
+		ids     []string
+		byId    map[string]map[string]interface{}
+		subtree map[string][]string
+	}
+
+	levels := make(map[string]*resolved, len(tree))
+	g, gctx := errgroup.WithContext(ctx)
+
+	for name, subpaths := range tree {
+		r := &resolved{
+			rel:     relations[resource][name],
+			ids:     make([]string, len(items)),
+			byId:    make(map[string]map[string]interface{}),
+			subtree: expandTree(subpaths),
+		}
+		levels[name] = r
+
+		var unique []string
+		add := func(id string) {
+			if _, ok := r.byId[id]; id == "" || ok {
+				return
+			}
+			r.byId[id] = nil
+			unique = append(unique, id)
+		}
+		for i, item := range items {
+			r.ids[i] = relatedId(item, r.rel.idKeys)
+			add(r.ids[i])
+			for _, element := range elementsOf(item, r.rel.itemsKey) {
+				add(relatedId(element, r.rel.idKeys))
+			}
+		}
+
+		if len(unique) == 0 {
+			continue
+		}
+
+		g.Go(func() error {
+			var found map[string]proto.Message
+			var err error
+			if r.rel.fetchMany != nil {
+				found, err = r.rel.fetchMany(h, gctx, unique)
+			} else {
+				found, err = h.fetchEach(gctx, r.rel.fetch, unique)
+			}
+			if err != nil {
+				return err
+			}
+
+			for id, m := range found {
+				r.byId[id] = toMap(m)
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	for name, r := range levels {
+		if len(r.subtree) > 0 {
+			ids := make([]string, 0, len(r.byId))
+			children := make([]map[string]interface{}, 0, len(r.byId))
+			for id, child := range r.byId {
+				if child != nil {
+					ids = append(ids, id)
+					children = append(children, child)
+				}
+			}
+
+			nested, err := h.resolve(ctx, r.rel.resource, children, r.subtree)
+			if err != nil {
+				return nil, err
+			}
+			for i := range children {
+				for key, value := range nested[i] {
+					r.byId[ids[i]][key] = value
+				}
+			}
+		}
+
+		for i, id := range r.ids {
+			if id != "" {
+				// a resource that no longer exists is embedded as null
+				embedded[i][name] = r.byId[id]
+			}
+			for _, element := range elementsOf(items[i], r.rel.itemsKey) {
+				if id := relatedId(element, r.rel.idKeys); id != "" {
+					element[name] = r.byId[id]
+				}
+			}
+		}
+	}
+
+	return embedded, nil
+}
+
+// fetchEach resolves ids with one call each, running at most the configured number of calls at a time.
+// Ids that don't exist are left out of the result.
+func (h Handler) fetchEach(ctx context.Context, fetch func(h Handler, ctx context.Context, id string) (proto.Message, error), ids []string) (map[string]proto.Message, error) {
+	var mu sync.Mutex
+	found := make(map[string]proto.Message, len(ids))
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(h.Cfg.ExpandConcurrency)
+
+	for _, id := range ids {
+		id := id
+		g.Go(func() error {
+			m, err := fetch(h, gctx, id)
+			if status.Code(err) == codes.NotFound {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			found[id] = m
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return found, nil
+}
+
+// expandTree groups dotted paths by their first name, mapping it to the paths below it
+func expandTree(paths []string) map[string][]string {
+	tree := make(map[string][]string)
+	for _, path := range paths {
+		name, rest, _ := strings.Cut(path, ".")
+		if _, ok := tree[name]; !ok {
+			tree[name] = nil
+		}
+		if rest != "" {
+			tree[name] = append(tree[name], rest)
+		}
+	}
+	return tree
+}
+
+// elementsOf returns the objects in the list under key, none when key is empty
+func elementsOf(item map[string]interface{}, key string) []map[string]interface{} {
+	list, _ := item[key].([]interface{})
+
+	var elements []map[string]interface{}
+	for _, value := range list {
+		if element, ok := value.(map[string]interface{}); ok {
+			elements = append(elements, element)
+		}
+	}
+	return elements
+}
+
+// relatedId returns the first non-empty id found under keys, which may be dotted
+func relatedId(item map[string]interface{}, keys []string) string {
+	for _, key := range keys {
+		var value interface{} = item
+		for _, name := range strings.Split(key, ".") {
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				value = nil
+				break
+			}
+			value = m[name]
+		}
+
+		if id, ok := value.(string); ok && id != "" {
+			return id
+		}
+	}
+	return ""
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/uacademy/e_commerce/api_gateway/config"
+	"github.com/uacademy/e_commerce/api_gateway/models"
+)
+
+func TestParseExpand(t *testing.T) {
+	tests := []struct {
+		name     string
+		expand   *string
+		maxDepth int
+		resource string
+		defaults []string
+		paths    []string
+		errors   []string
+	}{
+		{name: "absent uses the defaults", resource: "order", maxDepth: 2, defaults: []string{"product"}, paths: []string{"product"}},
+		{name: "empty expands nothing", expand: strp(""), resource: "order", maxDepth: 2, defaults: []string{"product"}},
+		{name: "single relation", expand: strp("category"), resource: "product", maxDepth: 2, paths: []string{"category"}},
+		{name: "nested relation", expand: strp("product,product.category"), resource: "order", maxDepth: 2, paths: []string{"product", "product.category"}},
+		{name: "duplicates and blanks", expand: strp(" category,,category "), resource: "product", maxDepth: 2, paths: []string{"category"}},
+		{name: "deeper than the limit", expand: strp("product.category"), resource: "order", maxDepth: 1, errors: []string{
+			`"product.category" is nested deeper than 1`,
+		}},
+		{name: "unknown relation", expand: strp("owner"), resource: "product", maxDepth: 2, errors: []string{
+			`"owner" can't be expanded on product`,
+		}},
+		{name: "unknown nested relation", expand: strp("product.owner"), resource: "order", maxDepth: 2, errors: []string{
+			`"owner" can't be expanded on product`,
+		}},
+		{name: "relation of another resource", expand: strp("product"), resource: "product", maxDepth: 2, errors: []string{
+			`"product" can't be expanded on product`,
+		}},
+		{name: "every problem at once", expand: strp("owner,product.category.x"), resource: "order", maxDepth: 2, errors: []string{
+			`"owner" can't be expanded on order`,
+			`"product.category.x" is nested deeper than 2`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := "/v1/" + tt.resource
+			if tt.expand != nil {
+				target += "?expand=" + url.QueryEscape(*tt.expand)
+			}
+			c, w := newTestContext(target)
+
+			h := Handler{Cfg: config.Config{ExpandMaxDepth: tt.maxDepth}}
+			paths, ok := h.parseExpand(c, tt.resource, tt.defaults...)
+
+			if ok != (tt.errors == nil) {
+				t.Fatalf("ok = %v, response %s", ok, w.Body.String())
+			}
+			if !ok {
+				var body models.JSONError
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+					t.Fatal(err)
+				}
+				var got []string
+				for _, fe := range body.Fields {
+					got = append(got, fe.Message)
+				}
+				if w.Code != http.StatusBadRequest || !reflect.DeepEqual(got, tt.errors) {
+					t.Errorf("response = %d %v, want %v", w.Code, got, tt.errors)
+				}
+				return
+			}
+
+			sort.Strings(paths)
+			if !reflect.DeepEqual(paths, tt.paths) {
+				t.Errorf("paths = %v, want %v", paths, tt.paths)
+			}
+		})
+	}
+}
+
+func TestSelectedPaths(t *testing.T) {
+	tests := []struct {
+		name   string
+		expand []string
+		mask   []string
+		want   []string
+	}{
+		{name: "relation selected as a whole", expand: []string{"product"}, mask: []string{"id", "product"}, want: []string{"product"}},
+		{name: "relation selected by a field of it", expand: []string{"product"}, mask: []string{"product.title"}, want: []string{"product"}},
+		{name: "relation not selected", expand: []string{"product"}, mask: []string{"id", "status"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := selectedPaths("order", tt.expand, tt.mask)
+			sort.Strings(got)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selected = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func strp(s string) *string {
+	return &s
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"github.com/uacademy/e_commerce/api_gateway/models"
+)
+
+// fieldMask parses the fields query parameter, e.g. fields=id,title,category.id, into a mask of m.
+// Fields may be named as in responses or as in the proto.
+// Names are checked against the descriptor of m; on an unknown one the response is already written and ok is false.
+// A nil mask selects the whole message.
+func fieldMask(c *gin.Context, m proto.Message) (mask *fieldmaskpb.FieldMask, ok bool) {
+	return parseFieldMask(c, m, "")
+}
+
+// expandFieldMask is fieldMask for a resource with relations that ?expand= can embed:
+// fields may also select a relation m doesn't have, as a whole or some of its fields, e.g. product.title.
+// Such paths are only for projecting responses, readMask leaves them out of the mask sent to the backend.
+func expandFieldMask(c *gin.Context, m proto.Message, resource string) (mask *fieldmaskpb.FieldMask, ok bool) {
+	return parseFieldMask(c, m, resource)
+}
+
+func parseFieldMask(c *gin.Context, m proto.Message, resource string) (mask *fieldmaskpb.FieldMask, ok bool) {
+	raw := c.Query("fields")
+	if raw == "" {
+		return nil, true
+	}
+
+	mask = &fieldmaskpb.FieldMask{}
+	var fields []models.FieldError
+
+	for _, path := range strings.Split(raw, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+
+		if err := mask.Append(m, protoPath(path)); err != nil {
+			name, _, _ := strings.Cut(path, ".")
+			if _, ok := relations[resource][name]; ok {
+				mask.Paths = append(mask.Paths, protoPath(path))
+				continue
+			}
+			fields = append(fields, fieldError(c, "fields", "unknown_field", path))
+		}
+	}
+
+	if len(fields) > 0 {
+		validationErrorResponse(c, "invalid_query", fields)
+		return nil, false
+	}
+
+	if len(mask.GetPaths()) == 0 {
+		return nil, true
+	}
+
+	mask.Normalize()
+
+	return mask, true
+}
+
+// projected renders m with only the fields mask selects, a nil mask selects them all
+func projected(mask *fieldmaskpb.FieldMask, m proto.Message) map[string]interface{} {
+	rendered := toMap(m)
+	if mask != nil {
+		projectMap(rendered, mask.GetPaths())
+	}
+	return rendered
+}
+
+// projectMap drops every key of a rendered message which paths don't select.
+// Backends are free to ignore the read mask they are sent, so responses are always projected here.
+func projectMap(m map[string]interface{}, paths []string) {
+	// selected maps a field name to the paths below it, an empty one selects the field as a whole
+	selected := make(map[string][]string)
+	for _, path := range paths {
+		name, rest, _ := strings.Cut(path, ".")
+		selected[name] = append(selected[name], rest)
+	}
+
+	for key, value := range m {
+		subpaths, ok := selected[key]
+		if !ok {
+			delete(m, key)
+			continue
+		}
+
+		if child, ok := value.(map[string]interface{}); ok && !selectsWhole(subpaths) {
+			projectMap(child, subpaths)
+		}
+	}
+}
+
+func selectsWhole(subpaths []string) bool {
+	for _, path := range subpaths {
+		if path == "" {
+			return true
+		}
+	}
+	return false
+}
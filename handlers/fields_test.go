@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/uacademy/e_commerce/api_gateway/models"
+	ecom "github.com/uacademy/e_commerce/api_gateway/proto-gen/e_commerce"
+)
+
+func TestParseFieldMask(t *testing.T) {
+	tests := []struct {
+		name     string
+		fields   string
+		resource string
+		paths    []string
+		unknown  []string
+	}{
+		{name: "absent selects everything", fields: ""},
+		{name: "only separators select everything", fields: " , ,"},
+		{name: "proto names", fields: "id,title", paths: []string{"id", "title"}},
+		{name: "published and camelCase names", fields: "description,createdAt", paths: []string{"created_at", "descrip"}},
+		{name: "nested path", fields: "category.category_title", paths: []string{"category.category_title"}},
+		{name: "whole message covers its fields", fields: "category.id,category", paths: []string{"category"}},
+		{name: "unknown field", fields: "id,colour", unknown: []string{`"colour" is not a known field`}},
+		{name: "unknown nested field", fields: "category.colour", unknown: []string{`"category.colour" is not a known field`}},
+		{name: "every unknown field at once", fields: "a,b", unknown: []string{`"a" is not a known field`, `"b" is not a known field`}},
+		{name: "relation without expand support", fields: "product.title", unknown: []string{`"product.title" is not a known field`}},
+		{name: "relation of the resource", resource: "order", fields: "id,product.title", paths: []string{"id", "product.title"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m proto.Message = &ecom.GetProductByIdResponse{}
+			if tt.resource == "order" {
+				m = &ecom.Order{}
+			}
+
+			c, w := newTestContext("/v1/" + tt.resource + "?fields=" + url.QueryEscape(tt.fields))
+			mask, ok := parseFieldMask(c, m, tt.resource)
+
+			if ok != (tt.unknown == nil) {
+				t.Fatalf("ok = %v, response %s", ok, w.Body.String())
+			}
+			if !ok {
+				var body models.JSONError
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+					t.Fatal(err)
+				}
+				var got []string
+				for _, fe := range body.Fields {
+					if fe.Field != "fields" {
+						t.Errorf("error on %q, want fields", fe.Field)
+					}
+					got = append(got, fe.Message)
+				}
+				if w.Code != http.StatusBadRequest || !reflect.DeepEqual(got, tt.unknown) {
+					t.Errorf("response = %d %v, want %v", w.Code, got, tt.unknown)
+				}
+				return
+			}
+
+			if got := mask.GetPaths(); !reflect.DeepEqual(got, tt.paths) {
+				t.Errorf("paths = %v, want %v", got, tt.paths)
+			}
+		})
+	}
+}
+
+func TestProjectMap(t *testing.T) {
+	rendered := func() map[string]interface{} {
+		return map[string]interface{}{
+			"id":    "p1",
+			"title": "Phone",
+			"category": map[string]interface{}{
+				"id":             "c1",
+				"category_title": "Phones",
+			},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		paths []string
+		want  map[string]interface{}
+	}{
+		{name: "top level", paths: []string{"id"}, want: map[string]interface{}{"id": "p1"}},
+		{name: "nested", paths: []string{"id", "category.category_title"}, want: map[string]interface{}{
+			"id":       "p1",
+			"category": map[string]interface{}{"category_title": "Phones"},
+		}},
+		{name: "whole message", paths: []string{"category"}, want: map[string]interface{}{
+			"category": map[string]interface{}{"id": "c1", "category_title": "Phones"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := rendered()
+			projectMap(m, tt.paths)
+			if !reflect.DeepEqual(m, tt.want) {
+				t.Errorf("projected = %v, want %v", m, tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,19 @@
 package handlers
 
-import "github.com/uacademy/e_commerce/api_gateway/clients"
+import (
+	"github.com/uacademy/e_commerce/api_gateway/audit"
+	"github.com/uacademy/e_commerce/api_gateway/cart"
+	"github.com/uacademy/e_commerce/api_gateway/clients"
+	"github.com/uacademy/e_commerce/api_gateway/config"
+	"github.com/uacademy/e_commerce/api_gateway/cursor"
+	"github.com/uacademy/e_commerce/api_gateway/money"
+)
 
 type Handler struct {
 	GrpcClients *clients.GrpcClients
+	AuditSink   audit.Sink
+	Cursors     *cursor.Signer
+	Cfg         config.Config
+	Rates       money.Rates
+	Carts       cart.Store
 }
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// languages the gateway has messages in, the first one is the fallback
+var languages = []language.Tag{language.English, language.Russian}
+
+var languageMatcher = language.NewMatcher(languages)
+
+// messages holds client-facing validation messages by language and key
+var messages = map[language.Tag]map[string]string{
+	language.English: {
+		"invalid_body":           "invalid request body",
+		"invalid_query":          "invalid query parameters",
+		"required":               "is required",
+		"min":                    "must be at least %s",
+		"max":                    "must be at most %s",
+		"min_length":             "must be at least %s characters long",
+		"max_length":             "must be at most %s characters long",
+		"min_items":              "must have at least %s items",
+		"max_items":              "must have at most %s items",
+		"excluded_with":          "must not be set together with %s",
+		"not_for_sale":           "has no price and can't be ordered",
+		"numeric":                "must be a number",
+		"integer":                "must be an integer",
+		"boolean":                "must be true or false",
+		"timestamp":              "must be an RFC 3339 timestamp",
+		"unique":                 "must not contain duplicates",
+		"oneof":                  "must be one of: %s",
+		"uuid":                   "must be a UUID",
+		"phone":                  "must be a phone number in international format, e.g. +998901234567",
+		"decimal":                "must be a non-negative decimal number, e.g. 10.50",
+		"iso4217":                "must be an ISO 4217 currency code, e.g. USD",
+		"precision":              "must have at most %v decimal places in its currency",
+		"amount_range":           "is too large",
+		"type":                   "must be of type %s",
+		"rule":                   "failed on the %q rule",
+		"unsupported":            "is not supported",
+		"unknown_param":          "is not a known parameter",
+		"unknown_field":          "%q is not a known field",
+		"not_before":             "must not be before %s",
+		"not_less":               "must not be less than %s",
+		"too_deep":               "%q is nested deeper than %d",
+		"not_expandable":         "%q can't be expanded on %s",
+		"invalid_cursor":         "invalid cursor",
+		"unsupported_currency":   "%s has no exchange rate",
+		"mixed_currencies":       "is priced in %s, other products of the order in %s",
+		"unsupported_media_type": "content type must be %s",
+		"invalid_patch":          "merge patch must be a JSON object",
+		"not_writable":           "can't be changed",
+		"id_mismatch":            "must match the id in the path, %s",
+		"not_found":              "does not exist",
+		"not_in_cart":            "%s is not in the cart",
+		"empty_cart":             "cart is empty",
+		"checkout_in_progress":   "the cart is already being checked out",
+		"cart_full":              "cart can hold at most %d products",
+		"cart_quantity":          "would make the quantity in the cart %d, at most %d is allowed",
+		"cursor_sort":            "cursor was issued for a different sort",
+		"cursor_filters":         "cursor was issued for a different listing or filters",
+		"cursor_expired":         "cursor has expired, start from the first page",
+	},
+	language.Russian: {
+		"invalid_body":           "некорректное тело запроса",
+		"invalid_query":          "некорректные параметры запроса",
+		"required":               "обязательное поле",
+		"min":                    "должно быть не меньше %s",
+		"max":                    "должно быть не больше %s",
+		"min_length":             "должно содержать не меньше %s символов",
+		"max_length":             "должно содержать не больше %s символов",
+		"min_items":              "должно содержать не меньше %s элементов",
+		"max_items":              "должно содержать не больше %s элементов",
+		"excluded_with":          "нельзя указывать вместе с %s",
+		"not_for_sale":           "нет в продаже",
+		"numeric":                "должно быть числом",
+		"integer":                "должно быть целым числом",
+		"boolean":                "должно быть true или false",
+		"timestamp":              "должно быть временем в формате RFC 3339",
+		"unique":                 "не должно содержать повторов",
+		"oneof":                  "должно быть одним из: %s",
+		"uuid":                   "должно быть UUID",
+		"phone":                  "должно быть номером телефона в международном формате, например +998901234567",
+		"decimal":                "должно быть неотрицательным десятичным числом, например 10.50",
+		"iso4217":                "должно быть кодом валюты ISO 4217, например USD",
+		"precision":              "должно содержать не больше %v знаков после запятой для своей валюты",
+		"amount_range":           "слишком большое значение",
+		"type":                   "должно иметь тип %s",
+		"rule":                   "не прошло проверку %q",
+		"unsupported":            "не поддерживается",
+		"unknown_param":          "неизвестный параметр",
+		"unknown_field":          "%q — неизвестное поле",
+		"not_before":             "не может быть раньше %s",
+		"not_less":               "не может быть меньше %s",
+		"too_deep":               "%q вложено глубже %d",
+		"not_expandable":         "%q нельзя раскрыть у %s",
+		"invalid_cursor":         "недействительный курсор",
+		"unsupported_currency":   "для %s нет курса обмена",
+		"mixed_currencies":       "цена в %s, у других товаров заказа в %s",
+		"unsupported_media_type": "тип содержимого должен быть %s",
+		"invalid_patch":          "патч должен быть JSON-объектом",
+		"not_writable":           "нельзя изменить",
+		"id_mismatch":            "должно совпадать с id в пути, %s",
+		"not_found":              "не существует",
+		"not_in_cart":            "%s нет в корзине",
+		"empty_cart":             "корзина пуста",
+		"checkout_in_progress":   "заказ по корзине уже оформляется",
+		"cart_full":              "в корзине может быть не больше %d товаров",
+		"cart_quantity":          "количество в корзине стало бы %d, допустимо не больше %d",
+		"cursor_sort":            "курсор выдан для другой сортировки",
+		"cursor_filters":         "курсор выдан для другого списка или фильтров",
+		"cursor_expired":         "срок действия курсора истёк, начните с первой страницы",
+	},
+}
+
+// requestLanguage picks the best supported language for the Accept-Language header
+func requestLanguage(c *gin.Context) language.Tag {
+	tags, _, _ := language.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+	_, index, _ := languageMatcher.Match(tags...)
+
+	return languages[index]
+}
+
+// localize formats the message for key in the language of the request
+func localize(c *gin.Context, key string, args ...interface{}) string {
+	format, ok := messages[requestLanguage(c)][key]
+	if !ok {
+		format = messages[languages[0]][key]
+	}
+
+	return fmt.Sprintf(format, args...)
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/uacademy/e_commerce/api_gateway/models"
+	"github.com/uacademy/e_commerce/api_gateway/money"
+	ecom "github.com/uacademy/e_commerce/api_gateway/proto-gen/e_commerce"
+)
+
+const displayCurrencyKey = "display_currency"
+
+// DisplayCurrency reads the currency query parameter, e.g. currency=EUR.
+// Money in the response then also carries its value converted with the configured exchange rates,
+// and amounts in the query are taken to be in that currency.
+func (h Handler) DisplayCurrency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		currency := strings.ToUpper(strings.TrimSpace(c.Query("currency")))
+		if currency == "" {
+			c.Next()
+			return
+		}
+
+		if !h.Rates.Has(currency) {
+			validationErrorResponse(c, "invalid_query", []models.FieldError{
+				fieldError(c, "currency", "unsupported_currency", currency),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set(displayCurrencyKey, currency)
+		c.Next()
+	}
+}
+
+// queryCurrency is the currency amounts in the query string are given in
+func (h Handler) queryCurrency(c *gin.Context) string {
+	if currency := c.GetString(displayCurrencyKey); currency != "" {
+		return currency
+	}
+	return h.Cfg.BaseCurrency
+}
+
+// protoMoney converts money that passed validation into minor units
+func protoMoney(m models.Money) *ecom.Money {
+	units, _ := money.Parse(m.Amount, m.Currency)
+
+	return &ecom.Money{
+		Units:    units,
+		Currency: m.Currency,
+	}
+}
+
+// queryAmount converts an amount from the query string into minor units of the base currency.
+// On failure the field error is returned.
+func (h Handler) queryAmount(c *gin.Context, field, amount string) (*ecom.Money, *models.FieldError) {
+	currency := h.queryCurrency(c)
+
+	units, err := money.Parse(amount, currency)
+	if err == nil {
+		units, err = h.Rates.Convert(units, currency, h.Cfg.BaseCurrency)
+	}
+	if err != nil {
+		fe := moneyFieldError(c, field, currency, err)
+		return nil, &fe
+	}
+
+	return &ecom.Money{
+		Units:    units,
+		Currency: h.Cfg.BaseCurrency,
+	}, nil
+}
+
+func moneyFieldError(c *gin.Context, field, currency string, err error) models.FieldError {
+	if errors.Is(err, money.ErrPrecision) {
+		return fieldError(c, field, "precision", money.Exponent(currency))
+	}
+	return fieldError(c, field, "amount_range")
+}
+
+// isMoney reports whether m is a rendered ecom.Money
+func isMoney(m map[string]interface{}) bool {
+	if len(m) != 2 {
+		return false
+	}
+	_, hasUnits := m["units"]
+	_, hasCurrency := m["currency"]
+	return hasUnits && hasCurrency
+}
+
+// moneyOf returns the currency and minor units of a rendered ecom.Money
+func moneyOf(m map[string]interface{}) (string, int64) {
+	currency, _ := m["currency"].(string)
+
+	// protojson renders int64 as a string
+	var units int64
+	switch v := m["units"].(type) {
+	case string:
+		units, _ = strconv.ParseInt(v, 10, 64)
+	case json.Number:
+		units, _ = v.Int64()
+	}
+
+	return currency, units
+}
+
+// jsonMoney renders a rendered ecom.Money as models.DisplayedMoney
+func (h Handler) jsonMoney(c *gin.Context, m map[string]interface{}) interface{} {
+	currency, units := moneyOf(m)
+
+	out := map[string]interface{}{
+		"amount":   money.Format(units, currency),
+		"currency": currency,
+	}
+
+	if display := c.GetString(displayCurrencyKey); display != "" {
+		// money in a currency without a rate is shown as it is
+		if converted, err := h.Rates.Convert(units, currency, display); err == nil {
+			out["display"] = map[string]interface{}{
+				"amount":   money.Format(converted, display),
+				"currency": display,
+			}
+		}
+	}
+
+	return out
+}
@@ -1,19 +1,23 @@
 package handlers
 
 import (
-	"strconv"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/proto"
 
-	"net/http"
-
+	"github.com/uacademy/e_commerce/api_gateway/cursor"
 	"github.com/uacademy/e_commerce/api_gateway/models"
+	"github.com/uacademy/e_commerce/api_gateway/money"
 	ecom "github.com/uacademy/e_commerce/api_gateway/proto-gen/e_commerce"
 )
 
 // CreateOrder godoc
 // @Summary     Create order
-// @Description create new order
+// @Description create new order of one or more products, priced at their current prices. All products must be priced in the same currency, the total is in it too
 // @Tags        orders
 // @Accept      json
 // @Produce     json
@@ -25,39 +29,133 @@ import (
 // @Router      /v1/order [post]
 func (h Handler) CreateOrder(c *gin.Context) {
 	var body models.CreateOrderModel
-	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{Error: err.Error()})
+	if !bindJSON(c, &body) {
 		return
 	}
 
-	_, err := h.GrpcClients.Product.GetProductById(c.Request.Context(), &ecom.GetProductByIdRequest{
-		Id: body.Product_id,
+	req := &ecom.CreateOrderRequest{
+		UserName:    body.User_name,
+		UserAddress: body.User_address,
+		UserPhone:   body.User_phone,
+	}
+
+	items := body.Items
+	single := body.Product_id != ""
+	if single {
+		items = []models.CreateOrderItemModel{{Product_id: body.Product_id, Quantity: body.Quantity}}
+		req.ProductId = body.Product_id
+		req.Quantity = body.Quantity
+	}
+	if len(items) == 0 {
+		validationErrorResponse(c, "invalid_body", []models.FieldError{
+			fieldError(c, "items", "min_items", "1"),
+		})
+		return
+	}
+
+	order, ok := h.placeOrder(c, req, items, single)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.JSONResult{
+		Message: "OK",
+		Data:    h.jsonValue(c, order),
 	})
+}
+
+// placeOrder prices items and creates the order of req with them; on failure the response is already written
+func (h Handler) placeOrder(c *gin.Context, req *ecom.CreateOrderRequest, items []models.CreateOrderItemModel, single bool) (*ecom.Order, bool) {
+	var ok bool
+	req.Items, req.Total, ok = h.priceOrderItems(c, items, single)
+	if !ok {
+		return nil, false
+	}
+
+	order, err := h.GrpcClients.Order.CreateOrder(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusBadRequest), models.JSONError{
 			Error: err.Error(),
 		})
-		return
+		return nil, false
 	}
 
-	order, err := h.GrpcClients.Order.CreateOrder(c.Request.Context(), &ecom.CreateOrderRequest{
-		ProductId:   body.Product_id,
-		Quantity:    body.Quantity,
-		UserName:    body.User_name,
-		UserAddress: body.User_address,
-		UserPhone:   body.User_phone,
-	})
+	return order, true
+}
+
+// priceOrderItems looks the products of items up in the catalog, all of them at once, and prices each line
+// at the current price of its product. The order total is charged, so it is never converted with the display rates:
+// all products must be priced in the same currency, which becomes the currency of the total.
+// Errors are reported on the fields of single-item orders when single is set; on failure the response is already written.
+func (h Handler) priceOrderItems(c *gin.Context, items []models.CreateOrderItemModel, single bool) ([]*ecom.OrderItem, *ecom.Money, bool) {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.Product_id
+	}
+
+	found, err := fetchProducts(h, c.Request.Context(), ids)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusInternalServerError), models.JSONError{
 			Error: err.Error(),
 		})
-		return
+		return nil, nil, false
 	}
 
-	c.JSON(http.StatusCreated, models.JSONResult{
-		Message: "OK",
-		Data:    order,
-	})
+	var fields []models.FieldError
+	orderItems := make([]*ecom.OrderItem, len(items))
+	total := &ecom.Money{}
+
+	for i, item := range items {
+		prefix := ""
+		if !single {
+			prefix = fmt.Sprintf("items[%d].", i)
+		}
+
+		product, _ := found[item.Product_id].(*ecom.Product)
+		if product == nil {
+			fields = append(fields, fieldError(c, prefix+"product_id", "not_found"))
+			continue
+		}
+		price := product.GetPrice()
+		if price == nil {
+			fields = append(fields, fieldError(c, prefix+"product_id", "not_for_sale"))
+			continue
+		}
+
+		if total.Currency == "" {
+			total.Currency = price.Currency
+		}
+		if price.Currency != total.Currency {
+			fields = append(fields, fieldError(c, prefix+"product_id", "mixed_currencies", price.Currency, total.Currency))
+			continue
+		}
+
+		lineTotal, err := money.Multiply(price.Units, int64(item.Quantity))
+		if err == nil {
+			total.Units, err = money.Add(total.Units, lineTotal)
+		}
+		if err != nil {
+			fields = append(fields, fieldError(c, prefix+"quantity", "amount_range"))
+			continue
+		}
+
+		orderItems[i] = &ecom.OrderItem{
+			ProductId: item.Product_id,
+			Quantity:  item.Quantity,
+			UnitPrice: price,
+			LineTotal: &ecom.Money{
+				Units:    lineTotal,
+				Currency: price.Currency,
+			},
+		}
+	}
+
+	if len(fields) > 0 {
+		validationErrorResponse(c, "invalid_body", fields)
+		return nil, nil, false
+	}
+
+	return orderItems, total, true
 }
 
 // ListOrders godoc
@@ -66,51 +164,125 @@ func (h Handler) CreateOrder(c *gin.Context) {
 // @Tags        orders
 // @Accept      json
 // @Produce     json
-// @Param       offset        query    string false "0"
-// @Param       limit         query    string false "10"
+// @Param       offset        query    int    false "0"
+// @Param       limit         query    int    false "10"
 // @Param       search        query    string false "smth"
+// @Param       cursor        query    string false "next_cursor of the previous page"
+// @Param       sort          query    string false "-created_at"
+// @Param       product_id    query    string false "Product ID"
+// @Param       user_phone    query    string false "+998901234567"
+// @Param       created_from  query    string false "2006-01-02T15:04:05Z"
+// @Param       created_to    query    string false "2006-01-02T15:04:05Z"
+// @Param       status        query    string false "pending,paid"
+// @Param       fields        query    string false "id,product_id,status"
+// @Param       expand        query    string false "product,product.category"
+// @Param       currency      query    string false "EUR"
 // @Param       Authorization header   string false "Authorization"
-// @Success     200           {object} models.JSONResult{data=[]models.Order}
+// @Success     200           {object} models.JSONListResult{data=[]models.Order}
 // @Failure     400           {object} models.JSONError
 // @Failure     500           {object} models.JSONError
 // @Router      /v1/order [get]
 func (h Handler) GetOrderList(c *gin.Context) {
-	offsetStr := c.DefaultQuery("offset", "0")
-	limitStr := c.DefaultQuery("limit", "10")
-	searchStr := c.DefaultQuery("search", "")
+	var query models.OrderListQuery
+	if !h.bindListQuery(c, &query) {
+		return
+	}
 
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{
-			Error: err.Error(),
+	mask, ok := expandFieldMask(c, &ecom.Order{}, "order")
+	if !ok {
+		return
+	}
+
+	// orders carry a summary of their product unless asked otherwise
+	expand, ok := h.parseExpand(c, "order", "product")
+	if !ok {
+		return
+	}
+
+	if !query.CreatedFrom.IsZero() && !query.CreatedTo.IsZero() && query.CreatedTo.Before(query.CreatedFrom) {
+		validationErrorResponse(c, "invalid_query", []models.FieldError{
+			fieldError(c, "created_to", "not_before", "created_from"),
 		})
 		return
 	}
 
-	limit, err := strconv.Atoi(limitStr)
+	req := &ecom.GetOrderListRequest{
+		Offset:    int32(query.Offset),
+		Limit:     int32(query.Limit),
+		Search:    query.Search,
+		Sort:      query.Sort,
+		ProductId: query.ProductId,
+		UserPhone: query.UserPhone,
+		Status:    query.Status,
+		ReadMask:  readMask(&ecom.Order{}, "order", mask, expand),
+	}
+	if !query.CreatedFrom.IsZero() {
+		req.CreatedFrom = query.CreatedFrom.Format(time.RFC3339)
+	}
+	if !query.CreatedTo.IsZero() {
+		req.CreatedTo = query.CreatedTo.Format(time.RFC3339)
+	}
+
+	sort := strings.Join(query.Sort, ",")
+	filters := cursor.FiltersHash(map[string][]string{
+		"search":       nonEmpty(query.Search),
+		"product_id":   nonEmpty(query.ProductId),
+		"user_phone":   nonEmpty(query.UserPhone),
+		"status":       query.Status,
+		"created_from": nonEmpty(req.CreatedFrom),
+		"created_to":   nonEmpty(req.CreatedTo),
+	})
+
+	if query.Cursor != "" {
+		cur, ok := h.decodeCursor(c, query.Cursor, "order", filters, sort)
+		if !ok {
+			return
+		}
+
+		query.Offset = 0
+		req.Offset = 0
+		req.CursorSortKey = cur.SortKey
+		req.CursorId = cur.LastId
+	}
+
+	orderList, err := h.GrpcClients.Order.GetOrderList(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusInternalServerError), models.JSONError{
 			Error: err.Error(),
 		})
 		return
 	}
 
-	orderList, err := h.GrpcClients.Order.GetOrderList(c.Request.Context(), &ecom.GetOrderListRequest{
-		Offset: int32(offset),
-		Limit:  int32(limit),
-		Search: searchStr,
-	})
+	p := page{
+		total:  int(orderList.Count),
+		offset: query.Offset,
+		limit:  query.Limit,
+		cursor: query.Cursor,
+	}
+	if orderList.NextId != "" {
+		p.nextCursor = h.Cursors.Encode(cursor.Cursor{
+			Resource: "order",
+			Filters:  filters,
+			Sort:     sort,
+			SortKey:  orderList.NextSortKey,
+			LastId:   orderList.NextId,
+		})
+	}
+
+	items := make([]proto.Message, len(orderList.Orders))
+	for i, order := range orderList.Orders {
+		items[i] = order
+	}
+
+	data, err := h.render(c.Request.Context(), "order", mask, expand, items...)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusInternalServerError), models.JSONError{
 			Error: err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, models.JSONResult{
-		Message: "OK",
-		Data:    orderList,
-	})
+	h.listResponse(c, data, p)
 }
 
 // GetOrder godoc
@@ -120,40 +292,49 @@ func (h Handler) GetOrderList(c *gin.Context) {
 // @Accept      json
 // @Produce     json
 // @Param       id            path     string true  "Order ID"
+// @Param       fields        query    string false "id,status,product.title"
+// @Param       expand        query    string false "product,product.category"
+// @Param       currency      query    string false "EUR"
 // @Param       Authorization header   string false "Authorization"
 // @Success     200           {object} models.JSONResult{data=models.PackedOrderModel}
+// @Failure     400           {object} models.JSONError
 // @Failure     404           {object} models.JSONError
 // @Router      /v1/order/{id} [get]
 func (h Handler) GetOrderById(c *gin.Context) {
 	id := c.Param("id")
 
+	mask, ok := expandFieldMask(c, &ecom.GetOrderByIdResponse{}, "order")
+	if !ok {
+		return
+	}
+
+	// the product used to be embedded unconditionally
+	expand, ok := h.parseExpand(c, "order", "product")
+	if !ok {
+		return
+	}
+
 	order, err := h.GrpcClients.Order.GetOrderById(c.Request.Context(), &ecom.GetOrderByIdRequest{
-		Id: id,
+		Id:       id,
+		ReadMask: readMask(&ecom.GetOrderByIdResponse{}, "order", mask, expand),
 	})
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusNotFound), models.JSONError{
 			Error: err.Error(),
 		})
 		return
 	}
 
-	product, err := h.GrpcClients.Product.GetProductById(c.Request.Context(), &ecom.GetProductByIdRequest{
-		Id: order.Product.Id,
-	})
+	data, err := h.render(c.Request.Context(), "order", mask, expand, order)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusInternalServerError), models.JSONError{
 			Error: err.Error(),
 		})
 		return
 	}
 
-	order.Product = &ecom.GetOrderByIdResponse_Product{
-		Id:    product.Id,
-		Title: product.Title,
-	}
-
 	c.JSON(http.StatusOK, models.JSONResult{
 		Message: "OK",
-		Data:    order,
+		Data:    h.jsonValue(c, data[0]),
 	})
 }
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/uacademy/e_commerce/api_gateway/cursor"
+	"github.com/uacademy/e_commerce/api_gateway/models"
+)
+
+// page describes the slice of a listing a response carries
+type page struct {
+	total  int
+	offset int
+	limit  int
+	// cursor the page was requested with, empty for offset pagination
+	cursor string
+	// nextCursor continues after the last item, empty on the last page or when unsupported
+	nextCursor string
+}
+
+// listResponse writes a page of items with pagination metadata and the matching Link header
+func (h Handler) listResponse(c *gin.Context, items interface{}, p page) {
+	// an empty page is [] rather than null
+	if v := reflect.ValueOf(items); v.Kind() == reflect.Slice && v.IsNil() {
+		items = reflect.MakeSlice(v.Type(), 0, 0).Interface()
+	}
+
+	pagination := models.Pagination{
+		Total:      p.total,
+		Offset:     p.offset,
+		Limit:      p.limit,
+		Cursor:     p.cursor,
+		NextCursor: p.nextCursor,
+	}
+
+	links := make([]string, 0, 4)
+	if p.cursor != "" {
+		// keyset pages can only be walked forward
+		if p.nextCursor != "" {
+			pagination.Next = cursorURL(c.Request.URL, p.nextCursor, p.limit)
+			links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pagination.Next))
+		}
+		links = append(links, fmt.Sprintf(`<%s>; rel="first"`, pageURL(c.Request.URL, 0, p.limit)))
+	} else if p.limit > 0 {
+		last := 0
+		if p.total > 0 {
+			last = (p.total - 1) / p.limit * p.limit
+		}
+
+		if p.offset+p.limit < p.total {
+			pagination.Next = pageURL(c.Request.URL, p.offset+p.limit, p.limit)
+			links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pagination.Next))
+		}
+		if p.offset > 0 {
+			prev := p.offset - p.limit
+			if prev < 0 {
+				prev = 0
+			}
+			pagination.Prev = pageURL(c.Request.URL, prev, p.limit)
+			links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pagination.Prev))
+		}
+
+		links = append(links,
+			fmt.Sprintf(`<%s>; rel="first"`, pageURL(c.Request.URL, 0, p.limit)),
+			fmt.Sprintf(`<%s>; rel="last"`, pageURL(c.Request.URL, last, p.limit)),
+		)
+	}
+
+	c.Header("Link", strings.Join(links, ", "))
+	c.Header("X-Total-Count", strconv.Itoa(p.total))
+
+	c.JSON(http.StatusOK, models.JSONListResult{
+		Message:    "OK",
+		Data:       h.jsonValue(c, items),
+		Pagination: pagination,
+	})
+}
+
+// pageURL keeps every query parameter of the current request but offset, limit and cursor
+func pageURL(current *url.URL, offset, limit int) string {
+	query := current.Query()
+	query.Del("cursor")
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+
+	return current.Path + "?" + query.Encode()
+}
+
+// cursorURL keeps every query parameter of the current request but offset, limit and cursor
+func cursorURL(current *url.URL, cursor string, limit int) string {
+	query := current.Query()
+	query.Del("offset")
+	query.Set("cursor", cursor)
+	query.Set("limit", strconv.Itoa(limit))
+
+	return current.Path + "?" + query.Encode()
+}
+
+// decodeCursor verifies a cursor and that it was issued for the same listing, filters and sort.
+// On failure the response is already written.
+func (h Handler) decodeCursor(c *gin.Context, token, resource, filters, sort string) (cursor.Cursor, bool) {
+	cur, err := h.Cursors.Decode(token)
+	key := ""
+	switch {
+	case errors.Is(err, cursor.ErrExpired):
+		key = "cursor_expired"
+	case err != nil:
+		key = "invalid_cursor"
+	case cur.Resource != resource || cur.Filters != filters:
+		key = "cursor_filters"
+	case cur.Sort != sort:
+		key = "cursor_sort"
+	}
+	if key != "" {
+		validationErrorResponse(c, "invalid_query", []models.FieldError{
+			fieldError(c, "cursor", key),
+		})
+		return cursor.Cursor{}, false
+	}
+
+	return cur, true
+}
+
+// nonEmpty returns s as a single filter value, or none when it is empty
+func nonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
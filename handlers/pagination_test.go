@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/uacademy/e_commerce/api_gateway/cursor"
+	"github.com/uacademy/e_commerce/api_gateway/models"
+)
+
+// newTestContext is a context for a GET of target, handlers write their response to the recorder
+func newTestContext(target string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	return c, w
+}
+
+func TestListResponseLinks(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		page   page
+		link   string
+		next   string
+		prev   string
+	}{
+		{
+			name:   "first page",
+			target: "/v1/product?search=phone",
+			page:   page{total: 25, offset: 0, limit: 10},
+			link: `</v1/product?limit=10&offset=10&search=phone>; rel="next", ` +
+				`</v1/product?limit=10&offset=0&search=phone>; rel="first", ` +
+				`</v1/product?limit=10&offset=20&search=phone>; rel="last"`,
+			next: "/v1/product?limit=10&offset=10&search=phone",
+		},
+		{
+			name:   "middle page",
+			target: "/v1/product?offset=10&limit=10",
+			page:   page{total: 25, offset: 10, limit: 10},
+			link: `</v1/product?limit=10&offset=20>; rel="next", ` +
+				`</v1/product?limit=10&offset=0>; rel="prev", ` +
+				`</v1/product?limit=10&offset=0>; rel="first", ` +
+				`</v1/product?limit=10&offset=20>; rel="last"`,
+			next: "/v1/product?limit=10&offset=20",
+			prev: "/v1/product?limit=10&offset=0",
+		},
+		{
+			name:   "last page",
+			target: "/v1/product?offset=20&limit=10",
+			page:   page{total: 25, offset: 20, limit: 10},
+			link: `</v1/product?limit=10&offset=10>; rel="prev", ` +
+				`</v1/product?limit=10&offset=0>; rel="first", ` +
+				`</v1/product?limit=10&offset=20>; rel="last"`,
+			prev: "/v1/product?limit=10&offset=10",
+		},
+		{
+			name:   "offset off the grid",
+			target: "/v1/product?offset=5&limit=10",
+			page:   page{total: 25, offset: 5, limit: 10},
+			link: `</v1/product?limit=10&offset=15>; rel="next", ` +
+				`</v1/product?limit=10&offset=0>; rel="prev", ` +
+				`</v1/product?limit=10&offset=0>; rel="first", ` +
+				`</v1/product?limit=10&offset=20>; rel="last"`,
+			next: "/v1/product?limit=10&offset=15",
+			prev: "/v1/product?limit=10&offset=0",
+		},
+		{
+			name:   "empty listing",
+			target: "/v1/product",
+			page:   page{total: 0, offset: 0, limit: 10},
+			link: `</v1/product?limit=10&offset=0>; rel="first", ` +
+				`</v1/product?limit=10&offset=0>; rel="last"`,
+		},
+		{
+			name:   "cursor page",
+			target: "/v1/product?cursor=abc&offset=3&limit=10",
+			page:   page{total: 25, limit: 10, cursor: "abc", nextCursor: "def"},
+			link: `</v1/product?cursor=def&limit=10>; rel="next", ` +
+				`</v1/product?limit=10&offset=0>; rel="first"`,
+			next: "/v1/product?cursor=def&limit=10",
+		},
+		{
+			name:   "last cursor page",
+			target: "/v1/product?cursor=abc&limit=10",
+			page:   page{total: 25, limit: 10, cursor: "abc"},
+			link:   `</v1/product?limit=10&offset=0>; rel="first"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newTestContext(tt.target)
+			Handler{}.listResponse(c, []string(nil), tt.page)
+
+			if got := w.Header().Get("Link"); got != tt.link {
+				t.Errorf("Link = %s\nwant %s", got, tt.link)
+			}
+			if got := w.Header().Get("X-Total-Count"); got != strconv.Itoa(tt.page.total) {
+				t.Errorf("X-Total-Count = %q, want %d", got, tt.page.total)
+			}
+
+			var body struct {
+				Data       []string          `json:"data"`
+				Pagination models.Pagination `json:"pagination"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Data == nil {
+				t.Error("data is null, want []")
+			}
+			if body.Pagination.Next != tt.next || body.Pagination.Prev != tt.prev {
+				t.Errorf("pagination next = %q, prev = %q, want %q and %q", body.Pagination.Next, body.Pagination.Prev, tt.next, tt.prev)
+			}
+		})
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	signer := cursor.NewSigner("secret", time.Hour)
+	h := Handler{Cursors: signer}
+	token := signer.Encode(cursor.Cursor{Resource: "product", Filters: "f", Sort: "-price", LastId: "p1"})
+
+	tests := []struct {
+		name     string
+		token    string
+		resource string
+		filters  string
+		sort     string
+		message  string
+	}{
+		{name: "same listing", token: token, resource: "product", filters: "f", sort: "-price"},
+		{name: "tampered", token: token + "x", resource: "product", filters: "f", sort: "-price", message: "invalid cursor"},
+		{name: "other resource", token: token, resource: "order", filters: "f", sort: "-price", message: "cursor was issued for a different listing or filters"},
+		{name: "other filters", token: token, resource: "product", filters: "g", sort: "-price", message: "cursor was issued for a different listing or filters"},
+		{name: "other sort", token: token, resource: "product", filters: "f", sort: "price", message: "cursor was issued for a different sort"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newTestContext("/v1/product")
+			cur, ok := h.decodeCursor(c, tt.token, tt.resource, tt.filters, tt.sort)
+
+			if ok != (tt.message == "") {
+				t.Fatalf("ok = %v, response %s", ok, w.Body.String())
+			}
+			if ok {
+				if cur.LastId != "p1" {
+					t.Errorf("cursor = %+v", cur)
+				}
+				return
+			}
+
+			var body models.JSONError
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != http.StatusBadRequest || len(body.Fields) != 1 || body.Fields[0].Field != "cursor" || body.Fields[0].Message != tt.message {
+				t.Errorf("response = %d %+v, want a cursor error %q", w.Code, body, tt.message)
+			}
+		})
+	}
+}
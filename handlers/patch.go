@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/uacademy/e_commerce/api_gateway/models"
+	"github.com/uacademy/e_commerce/api_gateway/money"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+// bindMergePatch applies the JSON merge patch (RFC 7396) in the request body to current, the resource as the backend
+// returned it, and decodes the result into obj, which is then validated like the body of a full update.
+// It returns the proto paths of the fields the patch changes; on failure the response is already written.
+func bindMergePatch(c *gin.Context, current proto.Message, obj interface{}) ([]string, bool) {
+	if ct := c.ContentType(); ct != mergePatchContentType && ct != binding.MIMEJSON {
+		c.JSON(http.StatusUnsupportedMediaType, models.JSONError{
+			Error: localize(c, "unsupported_media_type", mergePatchContentType),
+		})
+		return nil, false
+	}
+
+	var patch map[string]interface{}
+	if err := json.NewDecoder(c.Request.Body).Decode(&patch); err != nil || patch == nil {
+		validationErrorResponse(c, "invalid_patch", nil)
+		return nil, false
+	}
+
+	writable := writableFields(reflect.TypeOf(obj).Elem())
+
+	var fields []models.FieldError
+	paths := make([]string, 0, len(patch))
+	for name := range patch {
+		if !writable[name] {
+			fields = append(fields, fieldError(c, name, "not_writable"))
+			continue
+		}
+		paths = append(paths, protoPath(name))
+	}
+	sort.Strings(paths)
+
+	merged, _ := json.Marshal(mergePatch(document(toMap(current)), patch))
+	if err := json.Unmarshal(merged, obj); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			fields = append(fields, fieldError(c, typeErr.Field, "type", typeErr.Type.Kind().String()))
+		}
+	}
+
+	if err := binding.Validator.ValidateStruct(obj); err != nil {
+		for _, fe := range fieldErrors(c, err) {
+			if !hasFieldError(fields, fe.Field) {
+				fields = append(fields, fe)
+			}
+		}
+	}
+
+	if len(fields) > 0 {
+		validationErrorResponse(c, "invalid_body", fields)
+		return nil, false
+	}
+
+	return paths, true
+}
+
+// writableFields are the fields of an update model a patch may change, the id can't be
+func writableFields(t reflect.Type) map[string]bool {
+	writable := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" && name != "id" {
+			writable[name] = true
+		}
+	}
+	return writable
+}
+
+// mergePatch applies patch to target as RFC 7396 describes: objects are merged key by key,
+// null removes a key and any other value replaces the target
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, _ := target.(map[string]interface{})
+	merged := make(map[string]interface{}, len(targetObject)+len(patchObject))
+	for key, value := range targetObject {
+		merged[key] = value
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = mergePatch(merged[key], value)
+	}
+
+	return merged
+}
+
+// document reshapes a rendered proto the way the request models name its fields, which is what patches refer to
+func document(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if isMoney(v) {
+			currency, units := moneyOf(v)
+			return map[string]interface{}{
+				"amount":   money.Format(units, currency),
+				"currency": currency,
+			}
+		}
+
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if renamed, ok := renamedFields[key]; ok {
+				key = renamed
+			}
+			out[key] = document(value)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, value := range v {
+			out[i] = document(value)
+		}
+		return out
+	}
+
+	return v
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/uacademy/e_commerce/api_gateway/models"
+	ecom "github.com/uacademy/e_commerce/api_gateway/proto-gen/e_commerce"
+)
+
+func TestMergePatch(t *testing.T) {
+	// the examples of RFC 7396, appendix A, and a few more
+	tests := []struct {
+		target, patch, want string
+	}{
+		{target: `{"a":"b"}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{target: `{"a":"b"}`, patch: `{"b":"c"}`, want: `{"a":"b","b":"c"}`},
+		{target: `{"a":"b"}`, patch: `{"a":null}`, want: `{}`},
+		{target: `{"a":"b","b":"c"}`, patch: `{"a":null}`, want: `{"b":"c"}`},
+		{target: `{"a":["b"]}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{target: `{"a":"c"}`, patch: `{"a":["b"]}`, want: `{"a":["b"]}`},
+		{target: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, want: `{"a":{"b":"d"}}`},
+		{target: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, want: `{"a":[1]}`},
+		{target: `["a","b"]`, patch: `["c","d"]`, want: `["c","d"]`},
+		{target: `{"a":"b"}`, patch: `["c"]`, want: `["c"]`},
+		{target: `{"a":"foo"}`, patch: `null`, want: `null`},
+		{target: `{"a":"foo"}`, patch: `"bar"`, want: `"bar"`},
+		{target: `{"e":null}`, patch: `{"a":1}`, want: `{"a":1,"e":null}`},
+		{target: `[1,2]`, patch: `{"a":"b","c":null}`, want: `{"a":"b"}`},
+		{target: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, want: `{"a":{"bb":{}}}`},
+		// absent keys are left alone, nested objects are merged rather than replaced
+		{target: `{"title":"A","price":{"amount":"1.00","currency":"USD"}}`, patch: `{"price":{"amount":"2.00"}}`,
+			want: `{"title":"A","price":{"amount":"2.00","currency":"USD"}}`},
+		{target: `{"title":"A"}`, patch: `{}`, want: `{"title":"A"}`},
+	}
+
+	for _, tt := range tests {
+		var target, patch, want interface{}
+		for _, doc := range []struct {
+			raw string
+			v   *interface{}
+		}{{tt.target, &target}, {tt.patch, &patch}, {tt.want, &want}} {
+			if err := json.Unmarshal([]byte(doc.raw), doc.v); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if got := mergePatch(target, patch); !reflect.DeepEqual(got, want) {
+			t.Errorf("mergePatch(%s, %s) = %v, want %s", tt.target, tt.patch, got, tt.want)
+		}
+	}
+}
+
+func TestBindMergePatch(t *testing.T) {
+	current := &ecom.Product{
+		Id:         "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+		CategoryId: "4fa85f64-5717-4562-b3fc-2c963f66afa6",
+		Title:      "Phone",
+		Descrip:    "a phone",
+		Price:      &ecom.Money{Units: 10050, Currency: "USD"},
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		patch       string
+		code        int
+		paths       []string
+		want        models.UpdateProductModel
+		fields      []string
+	}{
+		{
+			name:  "absent fields are kept",
+			patch: `{"title":"Tablet"}`,
+			paths: []string{"title"},
+			want: models.UpdateProductModel{
+				Id: current.Id, CategoryId: current.CategoryId, Title: "Tablet", Descrip: "a phone",
+				Price: models.Money{Amount: "100.50", Currency: "USD"},
+			},
+		},
+		{
+			name:  "nested objects are merged",
+			patch: `{"price":{"amount":"5"}}`,
+			paths: []string{"price"},
+			want: models.UpdateProductModel{
+				Id: current.Id, CategoryId: current.CategoryId, Title: "Phone", Descrip: "a phone",
+				Price: models.Money{Amount: "5", Currency: "USD"},
+			},
+		},
+		{
+			name:        "null removes a field",
+			contentType: "application/json",
+			patch:       `{"description":null}`,
+			paths:       []string{"descrip"},
+			want: models.UpdateProductModel{
+				Id: current.Id, CategoryId: current.CategoryId, Title: "Phone",
+				Price: models.Money{Amount: "100.50", Currency: "USD"},
+			},
+		},
+		{name: "empty patch", patch: `{}`, paths: []string{}, want: models.UpdateProductModel{
+			Id: current.Id, CategoryId: current.CategoryId, Title: "Phone", Descrip: "a phone",
+			Price: models.Money{Amount: "100.50", Currency: "USD"},
+		}},
+		{name: "null on a required field", patch: `{"title":null}`, code: http.StatusBadRequest, fields: []string{"title"}},
+		{name: "id is not writable", patch: `{"id":"x","colour":"red"}`, code: http.StatusBadRequest, fields: []string{"colour", "id"}},
+		{name: "wrong type", patch: `{"title":5}`, code: http.StatusBadRequest, fields: []string{"title"}},
+		{name: "not an object", patch: `["title"]`, code: http.StatusBadRequest},
+		{name: "null document", patch: `null`, code: http.StatusBadRequest},
+		{name: "other content type", contentType: "text/plain", patch: `{}`, code: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newTestContext("/v1/product/" + current.Id)
+			c.Request.Method = http.MethodPatch
+			c.Request.Body = io.NopCloser(strings.NewReader(tt.patch))
+			contentType := tt.contentType
+			if contentType == "" {
+				contentType = mergePatchContentType
+			}
+			c.Request.Header.Set("Content-Type", contentType)
+
+			var body models.UpdateProductModel
+			paths, ok := bindMergePatch(c, current, &body)
+
+			if ok != (tt.code == 0) {
+				t.Fatalf("ok = %v, response %d %s", ok, w.Code, w.Body.String())
+			}
+			if !ok {
+				var resp models.JSONError
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				var fields []string
+				for _, fe := range resp.Fields {
+					fields = append(fields, fe.Field)
+				}
+				sort.Strings(fields)
+				if w.Code != tt.code || !reflect.DeepEqual(fields, tt.fields) {
+					t.Errorf("response = %d %v, want %d %v", w.Code, fields, tt.code, tt.fields)
+				}
+				return
+			}
+
+			if !reflect.DeepEqual(paths, tt.paths) {
+				t.Errorf("paths = %v, want %v", paths, tt.paths)
+			}
+			if body != tt.want {
+				t.Errorf("body = %+v, want %+v", body, tt.want)
+			}
+		})
+	}
+}
@@ -1,11 +1,18 @@
 package handlers
 
 import (
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 
 	"net/http"
-	"strconv"
 
+	"github.com/uacademy/e_commerce/api_gateway/cursor"
 	"github.com/uacademy/e_commerce/api_gateway/models"
 	ecom "github.com/uacademy/e_commerce/api_gateway/proto-gen/e_commerce"
 )
@@ -24,8 +31,7 @@ import (
 // @Router      /v1/product [post]
 func (h Handler) CreateProduct(c *gin.Context) {
 	var body models.CreateProductModel
-	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{Error: err.Error()})
+	if !bindJSON(c, &body) {
 		return
 	}
 
@@ -33,10 +39,10 @@ func (h Handler) CreateProduct(c *gin.Context) {
 		CategoryId: body.CategoryId,
 		Title:      body.Title,
 		Descrip:    body.Descrip,
-		Price:      body.Price,
+		Price:      protoMoney(body.Price),
 	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusBadRequest), models.JSONError{
 			Error: err.Error(),
 		})
 		return
@@ -44,7 +50,7 @@ func (h Handler) CreateProduct(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, models.JSONResult{
 		Message: "OK",
-		Data:    product,
+		Data:    h.jsonValue(c, product),
 	})
 }
 
@@ -55,18 +61,43 @@ func (h Handler) CreateProduct(c *gin.Context) {
 // @Accept      json
 // @Produce     json
 // @Param       id            path     string true  "Product ID"
+// @Param       fields        query    string false "id,title,category.category_title"
+// @Param       expand        query    string false "category"
+// @Param       currency      query    string false "EUR"
 // @Param       Authorization header   string false "Authorization"
 // @Success     200           {object} models.JSONResult{data=models.Product}
+// @Failure     400           {object} models.JSONError
 // @Failure     404           {object} models.JSONError
 // @Router      /v1/product/{id} [get]
 func (h Handler) GetProductById(c *gin.Context) {
 	id := c.Param("id")
 
+	mask, ok := expandFieldMask(c, &ecom.GetProductByIdResponse{}, "product")
+	if !ok {
+		return
+	}
+
+	expand, ok := h.parseExpand(c, "product")
+	if !ok {
+		return
+	}
+
 	product, err := h.GrpcClients.Product.GetProductById(c.Request.Context(), &ecom.GetProductByIdRequest{
-		Id: id,
+		Id:       id,
+		ReadMask: readMask(&ecom.GetProductByIdResponse{}, "product", mask, expand),
 	})
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusNotFound), models.JSONError{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	setResourceETag(c, product, product.GetCategory())
+
+	data, err := h.render(c.Request.Context(), "product", mask, expand, product)
+	if err != nil {
+		c.JSON(grpcStatus(err, http.StatusInternalServerError), models.JSONError{
 			Error: err.Error(),
 		})
 		return
@@ -74,7 +105,7 @@ func (h Handler) GetProductById(c *gin.Context) {
 
 	c.JSON(http.StatusOK, models.JSONResult{
 		Message: "OK",
-		Data:    product,
+		Data:    h.jsonValue(c, data[0]),
 	})
 }
 
@@ -84,87 +115,356 @@ func (h Handler) GetProductById(c *gin.Context) {
 // @Tags        products
 // @Accept      json
 // @Produce     json
-// @Param       offset        query    string false "0"
-// @Param       limit         query    string false "10"
+// @Param       offset        query    int    false "0"
+// @Param       limit         query    int    false "10"
 // @Param       search        query    string false "smth"
+// @Param       cursor        query    string false "next_cursor of the previous page"
+// @Param       sort          query    string false "price,-created_at"
+// @Param       category_id   query    string false "Category ID"
+// @Param       price_min     query    string false "10.5"
+// @Param       price_max     query    string false "100"
+// @Param       created_after query    string false "2006-01-02T15:04:05Z"
+// @Param       fields        query    string false "id,title,price"
+// @Param       expand        query    string false "category"
+// @Param       currency      query    string false "EUR"
 // @Param       Authorization header   string false "Authorization"
-// @Success     200           {object} models.JSONResult{data=[]models.Product}
+// @Success     200           {object} models.JSONListResult{data=[]models.Product}
 // @Failure     400           {object} models.JSONError
 // @Failure     500           {object} models.JSONError
 // @Router      /v1/product [get]
 func (h Handler) GetProductList(c *gin.Context) {
-	offsetStr := c.DefaultQuery("offset", "0")
-	limitStr := c.DefaultQuery("limit", "10")
-	searchStr := c.DefaultQuery("search", "")
+	var query models.ProductListQuery
+	if !h.bindListQuery(c, &query) {
+		return
+	}
 
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{
-			Error: err.Error(),
-		})
+	mask, ok := expandFieldMask(c, &ecom.Product{}, "product")
+	if !ok {
+		return
+	}
+
+	expand, ok := h.parseExpand(c, "product")
+	if !ok {
+		return
+	}
+
+	req := &ecom.GetProductListRequest{
+		Offset:     int32(query.Offset),
+		Limit:      int32(query.Limit),
+		Search:     query.Search,
+		Sort:       query.Sort,
+		CategoryId: query.CategoryId,
+		ReadMask:   readMask(&ecom.Product{}, "product", mask, expand),
+	}
+
+	// price bounds are given in the requested currency and compared in the base one
+	var fields []models.FieldError
+	if query.PriceMin != "" {
+		priceMin, fe := h.queryAmount(c, "price_min", query.PriceMin)
+		if fe != nil {
+			fields = append(fields, *fe)
+		}
+		req.PriceMin = priceMin
+	}
+	if query.PriceMax != "" {
+		priceMax, fe := h.queryAmount(c, "price_max", query.PriceMax)
+		if fe != nil {
+			fields = append(fields, *fe)
+		}
+		req.PriceMax = priceMax
+	}
+	if req.PriceMin != nil && req.PriceMax != nil && req.PriceMin.Units > req.PriceMax.Units {
+		fields = append(fields, fieldError(c, "price_max", "not_less", "price_min"))
+	}
+	if len(fields) > 0 {
+		validationErrorResponse(c, "invalid_query", fields)
 		return
 	}
+	if !query.CreatedAfter.IsZero() {
+		req.CreatedAfter = query.CreatedAfter.Format(time.RFC3339)
+	}
 
-	limit, err := strconv.Atoi(limitStr)
+	sort := strings.Join(query.Sort, ",")
+	// price bounds mean something else in another currency
+	var priceCurrency string
+	if query.PriceMin != "" || query.PriceMax != "" {
+		priceCurrency = h.queryCurrency(c)
+	}
+	filters := cursor.FiltersHash(map[string][]string{
+		"search":         nonEmpty(query.Search),
+		"category_id":    nonEmpty(query.CategoryId),
+		"price_min":      nonEmpty(query.PriceMin),
+		"price_max":      nonEmpty(query.PriceMax),
+		"price_currency": nonEmpty(priceCurrency),
+		"created_after":  nonEmpty(req.CreatedAfter),
+	})
+
+	if query.Cursor != "" {
+		cur, ok := h.decodeCursor(c, query.Cursor, "product", filters, sort)
+		if !ok {
+			return
+		}
+
+		query.Offset = 0
+		req.Offset = 0
+		req.CursorSortKey = cur.SortKey
+		req.CursorId = cur.LastId
+	}
+
+	productList, err := h.GrpcClients.Product.GetProductList(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusInternalServerError), models.JSONError{
 			Error: err.Error(),
 		})
 		return
 	}
 
-	productList, err := h.GrpcClients.Product.GetProductList(c.Request.Context(), &ecom.GetProductListRequest{
-		Offset: int32(offset),
-		Limit:  int32(limit),
-		Search: searchStr,
-	})
+	p := page{
+		total:  int(productList.Count),
+		offset: query.Offset,
+		limit:  query.Limit,
+		cursor: query.Cursor,
+	}
+	if productList.NextId != "" {
+		p.nextCursor = h.Cursors.Encode(cursor.Cursor{
+			Resource: "product",
+			Filters:  filters,
+			Sort:     sort,
+			SortKey:  productList.NextSortKey,
+			LastId:   productList.NextId,
+		})
+	}
+
+	items := make([]proto.Message, len(productList.Products))
+	for i, product := range productList.Products {
+		items[i] = product
+	}
+
+	data, err := h.render(c.Request.Context(), "product", mask, expand, items...)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusInternalServerError), models.JSONError{
 			Error: err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, models.JSONResult{
-		Message: "OK",
-		Data:    productList,
-	})
+	h.listResponse(c, data, p)
 }
 
 // UpdateProduct godoc
 // @Summary     Update product
-// @Description update product
+// @Description replace the editable fields of a product, the id in the body may be left out and without category_id the product stays in its category
 // @Tags        products
 // @Accept      json
 // @Produce     json
+// @Param       id            path     string                    true  "Product ID"
 // @Param       product       body     models.UpdateProductModel true  "Product body"
 // @Param       Authorization header   string                    false "Authorization"
+// @Param       If-Match      header   string                    false "ETag of the product"
 // @Success     200           {object} models.JSONResult{data=models.Product}
 // @Failure     400           {object} models.JSONError
 // @Failure     404           {object} models.JSONError
-// @Router      /v1/product [put]
+// @Failure     412           {object} models.JSONError
+// @Router      /v1/product/{id} [put]
 func (h Handler) UpdateProduct(c *gin.Context) {
+	body := models.UpdateProductModel{Id: c.Param("id")}
+	if !bindJSON(c, &body) || !checkPathId(c, body.Id) {
+		return
+	}
+
+	current, expectedVersion, ok := h.prepareProductUpdate(c, body.Id)
+	if !ok {
+		return
+	}
+
+	// clients from before the category could be changed don't send one, their products stay where they are
+	var updateMask *fieldmaskpb.FieldMask
+	var category versioned = current.GetCategory()
+	if body.CategoryId == "" {
+		updateMask = &fieldmaskpb.FieldMask{Paths: []string{"descrip", "price", "title"}}
+	} else if body.CategoryId != current.GetCategory().GetId() {
+		if category, ok = h.checkCategoryExists(c, body.CategoryId); !ok {
+			return
+		}
+	}
+
+	h.sendProductUpdate(c, category, &ecom.UpdateProductRequest{
+		Id:              body.Id,
+		CategoryId:      body.CategoryId,
+		Title:           body.Title,
+		Descrip:         body.Descrip,
+		Price:           protoMoney(body.Price),
+		UpdateMask:      updateMask,
+		ExpectedVersion: expectedVersion,
+	})
+}
+
+// UpdateProductLegacy godoc
+// @Summary     Update product
+// @Description change the title and price of a product with the id in the body, replaced by PUT /v1/product/{id}
+// @Tags        products
+// @Accept      json
+// @Produce     json
+// @Param       product       body     models.LegacyUpdateProductModel true  "Product body"
+// @Param       Authorization header   string                          false "Authorization"
+// @Param       If-Match      header   string                          false "ETag of the product"
+// @Success     200           {object} models.JSONResult{data=models.Product}
+// @Failure     400           {object} models.JSONError
+// @Failure     404           {object} models.JSONError
+// @Failure     412           {object} models.JSONError
+// @Deprecated
+// @Router      /v1/product [put]
+func (h Handler) UpdateProductLegacy(c *gin.Context) {
+	var body models.LegacyUpdateProductModel
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	current, expectedVersion, ok := h.prepareProductUpdate(c, body.Id)
+	if !ok {
+		return
+	}
+
+	// the route never changed other fields, so they are kept
+	h.sendProductUpdate(c, current.GetCategory(), &ecom.UpdateProductRequest{
+		Id:              body.Id,
+		Title:           body.Title,
+		Price:           protoMoney(body.Price),
+		UpdateMask:      &fieldmaskpb.FieldMask{Paths: []string{"title", "price"}},
+		ExpectedVersion: expectedVersion,
+	})
+}
+
+// PatchProduct godoc
+// @Summary     Patch product
+// @Description change some fields of a product, the body is a JSON merge patch (RFC 7396)
+// @Tags        products
+// @Accept      application/merge-patch+json
+// @Produce     json
+// @Param       id            path     string                    true  "Product ID"
+// @Param       product       body     models.UpdateProductModel true  "Fields to change"
+// @Param       Authorization header   string                    false "Authorization"
+// @Param       If-Match      header   string                    false "ETag of the product"
+// @Success     200           {object} models.JSONResult{data=models.Product}
+// @Failure     400           {object} models.JSONError
+// @Failure     404           {object} models.JSONError
+// @Failure     412           {object} models.JSONError
+// @Failure     415           {object} models.JSONError
+// @Router      /v1/product/{id} [patch]
+func (h Handler) PatchProduct(c *gin.Context) {
+	id := c.Param("id")
+
+	current, err := h.GrpcClients.Product.GetProductById(c.Request.Context(), &ecom.GetProductByIdRequest{
+		Id: id,
+	})
+	if err != nil {
+		c.JSON(grpcStatus(err, http.StatusNotFound), models.JSONError{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	h.auditBefore(c, productOf(current))
+	expectedVersion, ok := checkIfMatch(c, current, current.GetCategory())
+	if !ok {
+		return
+	}
+
 	var body models.UpdateProductModel
-	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{Error: err.Error()})
+	paths, ok := bindMergePatch(c, productOf(current), &body)
+	if !ok {
 		return
 	}
 
-	product, err := h.GrpcClients.Product.UpdateProduct(c.Request.Context(), &ecom.UpdateProductRequest{
-		Id:    body.Id,
-		Title: body.Title,
-		Price: body.Price,
+	// an empty patch changes nothing
+	if len(paths) == 0 {
+		h.productUpdated(c, productOf(current), current.GetCategory())
+		return
+	}
+
+	// a product can't be taken out of its category, only moved
+	if body.CategoryId == "" {
+		validationErrorResponse(c, "invalid_body", []models.FieldError{
+			fieldError(c, "category_id", "required"),
+		})
+		return
+	}
+	var category versioned = current.GetCategory()
+	if body.CategoryId != current.GetCategory().GetId() {
+		if category, ok = h.checkCategoryExists(c, body.CategoryId); !ok {
+			return
+		}
+	}
+
+	h.sendProductUpdate(c, category, &ecom.UpdateProductRequest{
+		Id:              id,
+		CategoryId:      body.CategoryId,
+		Title:           body.Title,
+		Descrip:         body.Descrip,
+		Price:           protoMoney(body.Price),
+		UpdateMask:      &fieldmaskpb.FieldMask{Paths: paths},
+		ExpectedVersion: expectedVersion,
+	})
+}
+
+// prepareProductUpdate fetches the product to be updated for the audit entry and for an If-Match
+// precondition, and returns it with the version to send with the update. On failure the response is already written.
+func (h Handler) prepareProductUpdate(c *gin.Context, id string) (*ecom.GetProductByIdResponse, int64, bool) {
+	current, err := h.GrpcClients.Product.GetProductById(c.Request.Context(), &ecom.GetProductByIdRequest{
+		Id: id,
+	})
+	if err != nil {
+		c.JSON(grpcStatus(err, http.StatusNotFound), models.JSONError{
+			Error: err.Error(),
+		})
+		return nil, 0, false
+	}
+	h.auditBefore(c, productOf(current))
+
+	expectedVersion, ok := checkIfMatch(c, current, current.GetCategory())
+	return current, expectedVersion, ok
+}
+
+// checkCategoryExists fetches the category a product is being moved into,
+// responding with 400 when it doesn't exist
+func (h Handler) checkCategoryExists(c *gin.Context, id string) (*ecom.GetCategoryByIdResponse, bool) {
+	category, err := h.GrpcClients.Category.GetCategoryById(c.Request.Context(), &ecom.GetCategoryByIdRequest{
+		Id: id,
 	})
+	if status.Code(err) == codes.NotFound {
+		validationErrorResponse(c, "invalid_body", []models.FieldError{
+			fieldError(c, "category_id", "not_found"),
+		})
+		return nil, false
+	}
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusInternalServerError), models.JSONError{
 			Error: err.Error(),
 		})
+		return nil, false
+	}
+
+	return category, true
+}
+
+// sendProductUpdate updates a product which is in category afterwards, the category is part of its ETag
+func (h Handler) sendProductUpdate(c *gin.Context, category versioned, req *ecom.UpdateProductRequest) {
+	product, err := h.GrpcClients.Product.UpdateProduct(c.Request.Context(), req)
+	if err != nil {
+		updateError(c, err, req.ExpectedVersion)
 		return
 	}
 
+	h.productUpdated(c, product, category)
+}
+
+// productUpdated responds with the product as it is after an update
+func (h Handler) productUpdated(c *gin.Context, product *ecom.Product, category versioned) {
+	c.Header("ETag", resourceETag(product, category))
+
 	c.JSON(http.StatusOK, models.JSONResult{
 		Message: "OK",
-		Data:    product,
+		Data:    h.jsonValue(c, product),
 	})
 }
 
@@ -186,14 +486,16 @@ func (h Handler) DeleteProduct(c *gin.Context) {
 		Id: id,
 	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusBadRequest), models.JSONError{
 			Error: err.Error(),
 		})
 		return
 	}
 
+	h.auditDeleted(c, product)
+
 	c.JSON(http.StatusOK, models.JSONResult{
 		Message: "OK",
-		Data:    product,
+		Data:    h.jsonValue(c, product),
 	})
 }
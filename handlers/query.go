@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"github.com/uacademy/e_commerce/api_gateway/models"
+)
+
+// bindListQuery fills a list query struct (embedding models.ListQuery) from the query string.
+// All problems are reported at once as field-level errors; on failure the response is already written.
+func (h Handler) bindListQuery(c *gin.Context, obj interface{}) bool {
+	value := reflect.ValueOf(obj).Elem()
+
+	list := listQueryOf(value)
+	if list != nil {
+		*list = models.ListQuery{Limit: h.Cfg.DefaultPageSize}
+	}
+
+	query := c.Request.URL.Query()
+	known := make(map[string]bool)
+
+	fields := setQueryFields(c, value, query, known)
+
+	if h.Cfg.StrictQueryParams {
+		for name := range query {
+			if !known[name] && !globalQueryParams[name] {
+				fields = append(fields, fieldError(c, name, "unknown_param"))
+			}
+		}
+	}
+
+	if err := binding.Validator.ValidateStruct(obj); err != nil {
+		for _, fe := range fieldErrors(c, err) {
+			// a value that could not be converted is already reported
+			if !hasFieldError(fields, fe.Field) {
+				fields = append(fields, fe)
+			}
+		}
+	}
+
+	if list != nil && list.Limit > h.Cfg.MaxPageSize && !hasFieldError(fields, "limit") {
+		fields = append(fields, fieldError(c, "limit", "max", strconv.Itoa(h.Cfg.MaxPageSize)))
+	}
+
+	if len(fields) > 0 {
+		validationErrorResponse(c, "invalid_query", fields)
+		return false
+	}
+
+	return true
+}
+
+// listQueryOf returns the paging part of a query struct, which is either models.ListQuery itself or embeds it
+func listQueryOf(value reflect.Value) *models.ListQuery {
+	if list, ok := value.Addr().Interface().(*models.ListQuery); ok {
+		return list
+	}
+	if field := value.FieldByName("ListQuery"); field.IsValid() {
+		return field.Addr().Interface().(*models.ListQuery)
+	}
+	return nil
+}
+
+func hasFieldError(fields []models.FieldError, name string) bool {
+	for _, fe := range fields {
+		if fe.Field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// globalQueryParams are accepted on every endpoint even in strict mode
+var globalQueryParams = map[string]bool{
+	"fields":   true,
+	"expand":   true,
+	"currency": true,
+}
+
+// setQueryFields converts the query values into the fields tagged with `form`, descending into embedded structs
+func setQueryFields(c *gin.Context, value reflect.Value, query map[string][]string, known map[string]bool) []models.FieldError {
+	var fields []models.FieldError
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, setQueryFields(c, value.Field(i), query, known)...)
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("form"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		known[name] = true
+
+		values, ok := query[name]
+		if !ok || len(values) == 0 || values[0] == "" {
+			continue
+		}
+
+		if key := setQueryField(value.Field(i), values); key != "" {
+			fields = append(fields, fieldError(c, name, key))
+		}
+	}
+
+	return fields
+}
+
+// setQueryField converts values into field, returning the message key of the problem if they don't fit
+func setQueryField(field reflect.Value, values []string) string {
+	raw := values[0]
+
+	switch field.Interface().(type) {
+	case time.Time:
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return "timestamp"
+		}
+		field.Set(reflect.ValueOf(t))
+		return ""
+	case []string:
+		items := make([]string, 0)
+		for _, v := range values {
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+		return ""
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return "integer"
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return "numeric"
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return "boolean"
+		}
+		field.SetBool(b)
+	default:
+		return "unsupported"
+	}
+
+	return ""
+}
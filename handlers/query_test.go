@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/uacademy/e_commerce/api_gateway/config"
+	"github.com/uacademy/e_commerce/api_gateway/models"
+)
+
+func TestBindListQuery(t *testing.T) {
+	h := Handler{Cfg: config.Config{DefaultPageSize: 10, MaxPageSize: 100, StrictQueryParams: true}}
+
+	tests := []struct {
+		name   string
+		target string
+		fields map[string]string
+	}{
+		{name: "defaults", target: "/v1/product"},
+		{name: "valid filters", target: "/v1/product?limit=50&offset=10&sort=-price,title&category_id=3fa85f64-5717-4562-b3fc-2c963f66afa6&price_min=1.50&created_after=2022-01-02T03:04:05Z"},
+		{name: "global params in strict mode", target: "/v1/product?fields=id&expand=category&currency=EUR"},
+		{name: "not an integer", target: "/v1/product?limit=ten", fields: map[string]string{
+			"limit": "must be an integer",
+		}},
+		{name: "limit below the minimum", target: "/v1/product?limit=0", fields: map[string]string{
+			"limit": "must be at least 1",
+		}},
+		{name: "limit above the page size", target: "/v1/product?limit=101", fields: map[string]string{
+			"limit": "must be at most 100",
+		}},
+		{name: "negative offset", target: "/v1/product?offset=-1", fields: map[string]string{
+			"offset": "must be at least 0",
+		}},
+		{name: "unknown sort", target: "/v1/product?sort=popularity", fields: map[string]string{
+			"sort[0]": "must be one of: price, -price, title, -title, created_at, -created_at, updated_at, -updated_at",
+		}},
+		{name: "malformed timestamp", target: "/v1/product?created_after=yesterday", fields: map[string]string{
+			"created_after": "must be an RFC 3339 timestamp",
+		}},
+		{name: "unknown parameter", target: "/v1/product?colour=red", fields: map[string]string{
+			"colour": "is not a known parameter",
+		}},
+		{name: "every problem at once", target: "/v1/product?limit=0&category_id=7&price_max=-1", fields: map[string]string{
+			"limit":       "must be at least 1",
+			"category_id": "must be a UUID",
+			"price_max":   "must be a non-negative decimal number, e.g. 10.50",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newTestContext(tt.target)
+
+			var query models.ProductListQuery
+			ok := h.bindListQuery(c, &query)
+
+			if ok != (tt.fields == nil) {
+				t.Fatalf("ok = %v, response %s", ok, w.Body.String())
+			}
+			if ok {
+				if query.Limit == 0 {
+					t.Error("limit was left at zero, want the default page size")
+				}
+				return
+			}
+
+			var body models.JSONError
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]string, len(body.Fields))
+			for _, fe := range body.Fields {
+				got[fe.Field] = fe.Message
+			}
+			if w.Code != http.StatusBadRequest || !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("response = %d %v, want %v", w.Code, got, tt.fields)
+			}
+		})
+	}
+}
+
+func TestBindListQueryLenient(t *testing.T) {
+	h := Handler{Cfg: config.Config{DefaultPageSize: 10, MaxPageSize: 100}}
+	c, w := newTestContext("/v1/order?colour=red&status=paid,shipped")
+
+	var query models.OrderListQuery
+	if !h.bindListQuery(c, &query) {
+		t.Fatalf("unknown parameter rejected outside strict mode: %s", w.Body.String())
+	}
+	if query.Limit != 10 || !reflect.DeepEqual(query.Status, []string{"paid", "shipped"}) {
+		t.Errorf("query = %+v, want the default limit and both statuses", query)
+	}
+}
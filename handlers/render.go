@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Naming policies for the keys of backend resources in responses
+const (
+	NamingSnakeCase = "snake_case"
+	NamingCamelCase = "camelCase"
+)
+
+// renamedFields maps proto field names to the names the models publish for them
+var renamedFields = map[string]string{
+	"descrip": "description",
+}
+
+// timestampLayouts are the formats backends are known to send timestamps in
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999",
+}
+
+var marshalOptions = protojson.MarshalOptions{
+	UseProtoNames:   true,
+	EmitUnpopulated: true,
+}
+
+// toMap renders m with protojson, keeping the proto field names which masks and expansions refer to
+func toMap(m proto.Message) map[string]interface{} {
+	b, _ := marshalOptions.Marshal(m)
+
+	var rendered map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	_ = decoder.Decode(&rendered)
+
+	return rendered
+}
+
+// jsonValue converts the data of a response so that backend resources look like the published models:
+// protos are rendered with protojson including unpopulated fields, keys follow the configured naming policy,
+// timestamps are RFC 3339 and money is a decimal amount with its currency.
+// Values which aren't protos or rendered protos are returned as they are.
+func (h Handler) jsonValue(c *gin.Context, v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case proto.Message:
+		if reflect.ValueOf(v).IsNil() {
+			return nil
+		}
+		return h.jsonValue(c, toMap(v))
+	case map[string]interface{}:
+		if v == nil {
+			return nil
+		}
+		if isMoney(v) {
+			return h.jsonMoney(c, v)
+		}
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if strings.HasSuffix(key, "_at") {
+				value = jsonTimestamp(value)
+			}
+			out[h.jsonKey(key)] = h.jsonValue(c, value)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, value := range v {
+			out[i] = h.jsonValue(c, value)
+		}
+		return out
+	}
+
+	// lists straight from a backend, e.g. []*ecom.Category
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Implements(reflect.TypeOf((*proto.Message)(nil)).Elem()) {
+		out := make([]interface{}, rv.Len())
+		for i := range out {
+			out[i] = h.jsonValue(c, rv.Index(i).Interface())
+		}
+		return out
+	}
+
+	return v
+}
+
+func (h Handler) jsonKey(name string) string {
+	if renamed, ok := renamedFields[name]; ok {
+		name = renamed
+	}
+
+	if h.Cfg.JSONNaming != NamingCamelCase {
+		return name
+	}
+
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// protoPath translates a field path written the way responses name fields, e.g. category.categoryTitle or
+// description, back to the proto field names
+func protoPath(path string) string {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		var b strings.Builder
+		for _, r := range name {
+			if unicode.IsUpper(r) {
+				b.WriteByte('_')
+				r = unicode.ToLower(r)
+			}
+			b.WriteRune(r)
+		}
+		name = b.String()
+
+		for protoName, published := range renamedFields {
+			if name == published {
+				name = protoName
+			}
+		}
+		names[i] = name
+	}
+	return strings.Join(names, ".")
+}
+
+// jsonTimestamp rewrites a timestamp string as RFC 3339, an empty one means it was never set.
+// Strings in an unknown format are kept.
+func jsonTimestamp(value interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+	if s == "" {
+		return nil
+	}
+
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format(time.RFC3339)
+		}
+	}
+
+	return s
+}
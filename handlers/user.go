@@ -2,9 +2,9 @@ package handlers
 
 import (
 	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 
 	"net/http"
-	"strconv"
 
 	"github.com/uacademy/e_commerce/api_gateway/models"
 	ecom "github.com/uacademy/e_commerce/api_gateway/proto-gen/e_commerce"
@@ -23,8 +23,7 @@ import (
 // @Router      /v1/user [post]
 func (h Handler) CreateUser(c *gin.Context) {
 	var body models.CreateUserModel
-	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{Error: err.Error()})
+	if !bindJSON(c, &body) {
 		return
 	}
 
@@ -34,7 +33,7 @@ func (h Handler) CreateUser(c *gin.Context) {
 		UserType: body.User_type,
 	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusBadRequest), models.JSONError{
 			Error: err.Error(),
 		})
 		return
@@ -42,7 +41,7 @@ func (h Handler) CreateUser(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, models.JSONResult{
 		Message: "OK",
-		Data:    user,
+		Data:    h.jsonValue(c, userMap(nil, user)),
 	})
 }
 
@@ -52,18 +51,26 @@ func (h Handler) CreateUser(c *gin.Context) {
 // @Tags        users
 // @Accept      json
 // @Produce     json
-// @Param       id  path     string true "User ID"
-// @Success     200 {object} models.JSONResult{data=models.User}
-// @Failure     404 {object} models.JSONError
+// @Param       id     path     string true  "User ID"
+// @Param       fields query    string false "id,username"
+// @Success     200    {object} models.JSONResult{data=models.User}
+// @Failure     400    {object} models.JSONError
+// @Failure     404    {object} models.JSONError
 // @Router      /v1/user/{id} [get]
 func (h Handler) GetUserById(c *gin.Context) {
 	id := c.Param("id")
 
+	mask, ok := fieldMask(c, &ecom.User{})
+	if !ok {
+		return
+	}
+
 	user, err := h.GrpcClients.Auth.GetUserByID(c.Request.Context(), &ecom.GetUserByIDRequest{
-		Id: id,
+		Id:       id,
+		ReadMask: mask,
 	})
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusNotFound), models.JSONError{
 			Error: err.Error(),
 		})
 		return
@@ -71,7 +78,7 @@ func (h Handler) GetUserById(c *gin.Context) {
 
 	c.JSON(http.StatusOK, models.JSONResult{
 		Message: "OK",
-		Data:    user,
+		Data:    h.jsonValue(c, userMap(mask, user)),
 	})
 }
 
@@ -81,41 +88,85 @@ func (h Handler) GetUserById(c *gin.Context) {
 // @Tags        users
 // @Accept      json
 // @Produce     json
-// @Param       offset query    string false "0"
-// @Param       limit  query    string false "10"
+// @Param       offset query    int    false "0"
+// @Param       limit  query    int    false "10"
 // @Param       search query    string false "smth"
-// @Success     200    {object} models.JSONResult{data=[]models.User}
+// @Param       fields query    string false "id,username"
+// @Success     200    {object} models.JSONListResult{data=[]models.User}
 // @Failure     400    {object} models.JSONError
 // @Failure     500    {object} models.JSONError
 // @Router      /v1/user [get]
 func (h Handler) GetUserList(c *gin.Context) {
-	offsetStr := c.DefaultQuery("offset", "0")
-	limitStr := c.DefaultQuery("limit", "10")
-	searchStr := c.DefaultQuery("search", "")
+	var query models.ListQuery
+	if !h.bindListQuery(c, &query) {
+		return
+	}
 
-	offset, err := strconv.Atoi(offsetStr)
+	mask, ok := fieldMask(c, &ecom.User{})
+	if !ok {
+		return
+	}
+
+	userList, err := h.GrpcClients.Auth.GetUserList(c.Request.Context(), &ecom.GetUserListRequest{
+		Offset:   int32(query.Offset),
+		Limit:    int32(query.Limit),
+		Search:   query.Search,
+		ReadMask: mask,
+	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusInternalServerError), models.JSONError{
 			Error: err.Error(),
 		})
 		return
 	}
 
-	limit, err := strconv.Atoi(limitStr)
+	data := make([]interface{}, len(userList.Users))
+	for i, user := range userList.Users {
+		data[i] = userMap(mask, user)
+	}
+
+	h.listResponse(c, data, page{
+		total:  int(userList.Count),
+		offset: query.Offset,
+		limit:  query.Limit,
+	})
+}
+
+// UpdateUser godoc
+// @Summary     Update user
+// @Description update user, the id in the body may be left out
+// @Tags        users
+// @Accept      json
+// @Produce     json
+// @Param       id   path     string                 true "User ID"
+// @Param       user body     models.UpdateUserModel true "User body"
+// @Success     200  {object} models.JSONResult{data=models.User}
+// @Failure     400  {object} models.JSONError
+// @Failure     404  {object} models.JSONError
+// @Router      /v1/user/{id} [put]
+func (h Handler) UpdateUser(c *gin.Context) {
+	body := models.UpdateUserModel{Id: c.Param("id")}
+	if !bindJSON(c, &body) || !checkPathId(c, body.Id) {
+		return
+	}
+
+	current, err := h.GrpcClients.Auth.GetUserByID(c.Request.Context(), &ecom.GetUserByIDRequest{
+		Id: body.Id,
+	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusNotFound), models.JSONError{
 			Error: err.Error(),
 		})
 		return
 	}
+	h.auditBefore(c, current)
 
-	userList, err := h.GrpcClients.Auth.GetUserList(c.Request.Context(), &ecom.GetUserListRequest{
-		Offset: int32(offset),
-		Limit:  int32(limit),
-		Search: searchStr,
+	user, err := h.GrpcClients.Auth.UpdateUser(c.Request.Context(), &ecom.UpdateUserRequest{
+		Id:       body.Id,
+		Password: body.Password,
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusNotFound), models.JSONError{
 			Error: err.Error(),
 		})
 		return
@@ -123,13 +174,13 @@ func (h Handler) GetUserList(c *gin.Context) {
 
 	c.JSON(http.StatusOK, models.JSONResult{
 		Message: "OK",
-		Data:    userList,
+		Data:    h.jsonValue(c, userMap(nil, user)),
 	})
 }
 
-// UpdateUser godoc
+// UpdateUserLegacy godoc
 // @Summary     Update user
-// @Description update user
+// @Description update user with the id in the body, replaced by PUT /v1/user/{id}
 // @Tags        users
 // @Accept      json
 // @Produce     json
@@ -137,20 +188,61 @@ func (h Handler) GetUserList(c *gin.Context) {
 // @Success     200  {object} models.JSONResult{data=models.User}
 // @Failure     400  {object} models.JSONError
 // @Failure     404  {object} models.JSONError
+// @Deprecated
 // @Router      /v1/user [put]
-func (h Handler) UpdateUser(c *gin.Context) {
+func (h Handler) UpdateUserLegacy(c *gin.Context) {
+	h.UpdateUser(c)
+}
+
+// PatchUser godoc
+// @Summary     Patch user
+// @Description change some fields of a user, the body is a JSON merge patch (RFC 7396)
+// @Tags        users
+// @Accept      application/merge-patch+json
+// @Produce     json
+// @Param       id   path     string                 true "User ID"
+// @Param       user body     models.UpdateUserModel true "Fields to change"
+// @Success     200  {object} models.JSONResult{data=models.User}
+// @Failure     400  {object} models.JSONError
+// @Failure     404  {object} models.JSONError
+// @Failure     415  {object} models.JSONError
+// @Router      /v1/user/{id} [patch]
+func (h Handler) PatchUser(c *gin.Context) {
+	id := c.Param("id")
+
+	current, err := h.GrpcClients.Auth.GetUserByID(c.Request.Context(), &ecom.GetUserByIDRequest{
+		Id: id,
+	})
+	if err != nil {
+		c.JSON(grpcStatus(err, http.StatusNotFound), models.JSONError{
+			Error: err.Error(),
+		})
+		return
+	}
+	h.auditBefore(c, current)
+
 	var body models.UpdateUserModel
-	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{Error: err.Error()})
+	paths, ok := bindMergePatch(c, current, &body)
+	if !ok {
+		return
+	}
+
+	// an empty patch changes nothing
+	if len(paths) == 0 {
+		c.JSON(http.StatusOK, models.JSONResult{
+			Message: "OK",
+			Data:    h.jsonValue(c, userMap(nil, current)),
+		})
 		return
 	}
 
 	user, err := h.GrpcClients.Auth.UpdateUser(c.Request.Context(), &ecom.UpdateUserRequest{
-		Id:       body.Id,
-		Password: body.Password,
+		Id:         id,
+		Password:   body.Password,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: paths},
 	})
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusNotFound), models.JSONError{
 			Error: err.Error(),
 		})
 		return
@@ -158,7 +250,7 @@ func (h Handler) UpdateUser(c *gin.Context) {
 
 	c.JSON(http.StatusOK, models.JSONResult{
 		Message: "OK",
-		Data:    user,
+		Data:    h.jsonValue(c, userMap(nil, user)),
 	})
 }
 
@@ -179,14 +271,24 @@ func (h Handler) DeleteUser(c *gin.Context) {
 		Id: id,
 	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.JSONError{
+		c.JSON(grpcStatus(err, http.StatusBadRequest), models.JSONError{
 			Error: err.Error(),
 		})
 		return
 	}
 
+	h.auditDeleted(c, user)
+
 	c.JSON(http.StatusOK, models.JSONResult{
 		Message: "OK",
-		Data:    user,
+		Data:    h.jsonValue(c, userMap(nil, user)),
 	})
 }
+
+// userMap renders a user for a response projected with mask. The password hash is stripped
+// explicitly, it would otherwise be rendered as an unpopulated field when the backend leaves it out.
+func userMap(mask *fieldmaskpb.FieldMask, user *ecom.User) map[string]interface{} {
+	rendered := projected(mask, user)
+	delete(rendered, "password")
+	return rendered
+}
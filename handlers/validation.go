@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/uacademy/e_commerce/api_gateway/models"
+	"github.com/uacademy/e_commerce/api_gateway/money"
+)
+
+var (
+	// phone numbers in international format, e.g. +998901234567
+	phoneRegex = regexp.MustCompile(`^\+?[0-9]{7,15}$`)
+	// non-negative decimal amounts, e.g. 10.50
+	decimalRegex = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+	// ISO 4217 codes are three capital letters
+	currencyRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		// report fields by the names clients use rather than the Go ones
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"form", "json"} {
+				name := strings.Split(field.Tag.Get(tag), ",")[0]
+				if name != "" && name != "-" {
+					return name
+				}
+			}
+			return field.Name
+		})
+
+		_ = v.RegisterValidation("phone", matches(phoneRegex))
+		_ = v.RegisterValidation("decimal", matches(decimalRegex))
+
+		v.RegisterStructValidation(moneyPrecision, models.Money{})
+	}
+}
+
+// moneyPrecision checks that an amount fits into the minor units of its currency,
+// malformed amounts and currencies are left to their field rules
+func moneyPrecision(sl validator.StructLevel) {
+	m := sl.Current().Interface().(models.Money)
+	if !decimalRegex.MatchString(m.Amount) || !currencyRegex.MatchString(m.Currency) {
+		return
+	}
+
+	_, err := money.Parse(m.Amount, m.Currency)
+	switch {
+	case errors.Is(err, money.ErrPrecision):
+		sl.ReportError(m.Amount, "amount", "Amount", "precision", strconv.Itoa(money.Exponent(m.Currency)))
+	case err != nil:
+		sl.ReportError(m.Amount, "amount", "Amount", "amount_range", "")
+	}
+}
+
+func matches(re *regexp.Regexp) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return re.MatchString(fl.Field().String())
+	}
+}
+
+// bindJSON decodes the request body into obj and validates it against its binding tags.
+// On failure the response is already written.
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	err := c.ShouldBindJSON(obj)
+	if err == nil {
+		return true
+	}
+
+	fields := fieldErrors(c, err)
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		fields = append(fields, fieldError(c, typeErr.Field, "type", typeErr.Type.Kind().String()))
+	}
+
+	validationErrorResponse(c, "invalid_body", fields)
+	return false
+}
+
+// checkPathId responds with 400 when the id in the body of an update differs from the one in its path
+func checkPathId(c *gin.Context, bodyId string) bool {
+	if id := c.Param("id"); id != "" && bodyId != id {
+		validationErrorResponse(c, "invalid_body", []models.FieldError{
+			fieldError(c, "id", "id_mismatch", id),
+		})
+		return false
+	}
+	return true
+}
+
+// validationErrorResponse aborts with 400 and one entry per invalid field, key picks the message
+func validationErrorResponse(c *gin.Context, key string, fields []models.FieldError) {
+	c.JSON(http.StatusBadRequest, models.JSONError{
+		Error:  localize(c, key),
+		Fields: fields,
+	})
+}
+
+// fieldError reports a field in the language of the request
+func fieldError(c *gin.Context, field, key string, args ...interface{}) models.FieldError {
+	return models.FieldError{
+		Field:   field,
+		Message: localize(c, key, args...),
+	}
+}
+
+// fieldErrors converts validator errors into field-level errors; other errors yield nil
+func fieldErrors(c *gin.Context, err error) []models.FieldError {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	fields := make([]models.FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fields = append(fields, validatorFieldError(c, fe))
+	}
+
+	return fields
+}
+
+// fieldPath is the dotted path of the field within the payload, e.g. price.amount.
+// The root struct and embedded ones appear under their Go names, which are capitalized, and are left out.
+func fieldPath(fe validator.FieldError) string {
+	names := strings.Split(fe.Namespace(), ".")
+
+	path := make([]string, 0, len(names))
+	for _, name := range names[1:] {
+		if name != "" && !unicode.IsUpper(rune(name[0])) {
+			path = append(path, name)
+		}
+	}
+
+	return strings.Join(path, ".")
+}
+
+func validatorFieldError(c *gin.Context, fe validator.FieldError) models.FieldError {
+	field := fieldPath(fe)
+
+	switch fe.Tag() {
+	case "required", "numeric", "unique", "uuid", "phone", "decimal", "iso4217", "amount_range":
+		return fieldError(c, field, fe.Tag())
+	case "required_with", "required_without":
+		return fieldError(c, field, "required")
+	case "excluded_with":
+		return fieldError(c, field, "excluded_with", strings.ToLower(fe.Param()))
+	case "min", "max":
+		switch fe.Kind() {
+		case reflect.String:
+			return fieldError(c, field, fe.Tag()+"_length", fe.Param())
+		case reflect.Slice:
+			return fieldError(c, field, fe.Tag()+"_items", fe.Param())
+		}
+		return fieldError(c, field, fe.Tag(), fe.Param())
+	case "oneof":
+		return fieldError(c, field, "oneof", strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "precision":
+		return fieldError(c, field, "precision", fe.Param())
+	}
+
+	return fieldError(c, field, "rule", fe.Tag())
+}
@@ -0,0 +1,97 @@
+package loadshed
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/uacademy/e_commerce/api_gateway/config"
+)
+
+// Priority of a request when the gateway is saturated
+type Priority int
+
+const (
+	// PriorityLow requests may only use part of the limit, e.g. catalog browsing
+	PriorityLow Priority = iota
+	// PriorityHigh requests may use the whole limit, e.g. checkout
+	PriorityHigh
+)
+
+// Limiter is an AIMD concurrency limiter.
+// The limit grows by one for every limit-many requests completing under the latency target
+// and is multiplied by backoff when one completes over it or fails. Requests which were already
+// in flight at the last decrease are ignored for the next one, so a burst of slow requests
+// lowers the limit once rather than once per request.
+type Limiter struct {
+	minLimit      float64
+	maxLimit      float64
+	backoff       float64
+	latencyTarget time.Duration
+	lowShare      float64
+
+	mu          sync.Mutex
+	limit       float64
+	inFlight    int
+	decreasedAt time.Time
+}
+
+// New ...
+func New(cfg config.Config) *Limiter {
+	return &Limiter{
+		minLimit:      float64(cfg.LoadShedMinLimit),
+		maxLimit:      float64(cfg.LoadShedMaxLimit),
+		backoff:       cfg.LoadShedBackoff,
+		latencyTarget: cfg.LoadShedLatencyTarget,
+		lowShare:      cfg.LoadShedLowPriorityShare,
+		limit:         float64(cfg.LoadShedInitialLimit),
+	}
+}
+
+// Acquire reserves a slot for a request of the given priority.
+// When it returns false the request must be rejected; otherwise release must be called
+// with the observed latency and whether the request failed because of overload.
+func (l *Limiter) Acquire(priority Priority) (release func(latency time.Duration, overloaded bool), ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit := l.limit
+	if priority == PriorityLow {
+		// keep headroom for high priority traffic
+		limit = math.Max(1, math.Floor(l.limit*l.lowShare))
+	}
+
+	if float64(l.inFlight) >= limit {
+		return nil, false
+	}
+
+	l.inFlight++
+
+	return l.release, true
+}
+
+func (l *Limiter) release(latency time.Duration, overloaded bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+
+	if overloaded || latency > l.latencyTarget {
+		now := time.Now()
+		if now.Add(-latency).After(l.decreasedAt) {
+			l.limit = math.Max(l.minLimit, l.limit*l.backoff)
+			l.decreasedAt = now
+		}
+		return
+	}
+
+	l.limit = math.Min(l.maxLimit, l.limit+1/l.limit)
+}
+
+// Limit returns the current limit and number of requests in flight
+func (l *Limiter) Limit() (float64, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.limit, l.inFlight
+}
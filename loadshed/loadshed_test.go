@@ -0,0 +1,128 @@
+package loadshed
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/uacademy/e_commerce/api_gateway/config"
+)
+
+func newTestLimiter(limit int) *Limiter {
+	return New(config.Config{
+		LoadShedInitialLimit:     limit,
+		LoadShedMinLimit:         2,
+		LoadShedMaxLimit:         20,
+		LoadShedBackoff:          0.5,
+		LoadShedLatencyTarget:    100 * time.Millisecond,
+		LoadShedLowPriorityShare: 0.5,
+	})
+}
+
+func TestLimiterAcquire(t *testing.T) {
+	tests := []struct {
+		name     string
+		priority Priority
+		limit    int
+		admitted int
+	}{
+		{name: "high priority uses the whole limit", priority: PriorityHigh, limit: 10, admitted: 10},
+		{name: "low priority keeps headroom", priority: PriorityLow, limit: 10, admitted: 5},
+		{name: "low priority always gets one slot", priority: PriorityLow, limit: 1, admitted: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestLimiter(tt.limit)
+
+			admitted := 0
+			for i := 0; i < tt.limit*2; i++ {
+				if _, ok := l.Acquire(tt.priority); ok {
+					admitted++
+				}
+			}
+
+			if admitted != tt.admitted {
+				t.Errorf("admitted %d, want %d", admitted, tt.admitted)
+			}
+			if _, inFlight := l.Limit(); inFlight != tt.admitted {
+				t.Errorf("in flight = %d, want %d", inFlight, tt.admitted)
+			}
+		})
+	}
+}
+
+func TestLimiterAIMD(t *testing.T) {
+	tests := []struct {
+		name       string
+		latency    time.Duration
+		overloaded bool
+		want       float64
+	}{
+		{name: "fast requests increase additively", latency: time.Millisecond, want: 10 + 1.0/10},
+		{name: "slow requests decrease multiplicatively", latency: time.Second, want: 5},
+		{name: "overload decreases multiplicatively", latency: time.Millisecond, overloaded: true, want: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestLimiter(10)
+
+			release, ok := l.Acquire(PriorityHigh)
+			if !ok {
+				t.Fatal("request was rejected")
+			}
+			release(tt.latency, tt.overloaded)
+
+			limit, inFlight := l.Limit()
+			if math.Abs(limit-tt.want) > 1e-9 {
+				t.Errorf("limit = %v, want %v", limit, tt.want)
+			}
+			if inFlight != 0 {
+				t.Errorf("in flight = %d after release", inFlight)
+			}
+		})
+	}
+}
+
+func TestLimiterDecreasesOncePerBurst(t *testing.T) {
+	l := newTestLimiter(16)
+
+	releases := make([]func(time.Duration, bool), 4)
+	for i := range releases {
+		release, ok := l.Acquire(PriorityHigh)
+		if !ok {
+			t.Fatal("request was rejected")
+		}
+		releases[i] = release
+	}
+
+	// all of them were in flight when the first one lowered the limit
+	for _, release := range releases {
+		release(time.Second, false)
+	}
+
+	if limit, _ := l.Limit(); limit != 8 {
+		t.Errorf("limit = %v, want a single decrease to 8", limit)
+	}
+}
+
+func TestLimiterBounds(t *testing.T) {
+	l := newTestLimiter(3)
+	for i := 0; i < 5; i++ {
+		release, _ := l.Acquire(PriorityHigh)
+		release(0, true)
+		// requests started after the last decrease may lower it again
+		time.Sleep(time.Millisecond)
+	}
+	if limit, _ := l.Limit(); limit != 2 {
+		t.Errorf("limit = %v, want the minimum 2", limit)
+	}
+
+	l = newTestLimiter(20)
+	release, _ := l.Acquire(PriorityHigh)
+	release(0, false)
+	if limit, _ := l.Limit(); limit != 20 {
+		t.Errorf("limit = %v, want the maximum 20", limit)
+	}
+}
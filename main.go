@@ -1,17 +1,34 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"     // swagger embed files
 	ginSwagger "github.com/swaggo/gin-swagger" // gin-swagger middleware
 
+	"github.com/uacademy/e_commerce/api_gateway/audit"
+	"github.com/uacademy/e_commerce/api_gateway/cache"
+	"github.com/uacademy/e_commerce/api_gateway/cart"
 	"github.com/uacademy/e_commerce/api_gateway/clients"
 	"github.com/uacademy/e_commerce/api_gateway/config"
+	"github.com/uacademy/e_commerce/api_gateway/cursor"
 	"github.com/uacademy/e_commerce/api_gateway/docs" // docs is generated by Swag CLI, you have to import it.
 	"github.com/uacademy/e_commerce/api_gateway/handlers"
+	"github.com/uacademy/e_commerce/api_gateway/loadshed"
+	"github.com/uacademy/e_commerce/api_gateway/metrics"
+	"github.com/uacademy/e_commerce/api_gateway/middlewares"
+	"github.com/uacademy/e_commerce/api_gateway/money"
+	"github.com/uacademy/e_commerce/api_gateway/ratelimit"
+	"github.com/uacademy/e_commerce/api_gateway/redisdb"
+	"github.com/uacademy/e_commerce/api_gateway/reporting"
+	"github.com/uacademy/e_commerce/api_gateway/tracing"
 )
 
 func main() {
@@ -21,12 +38,46 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// expanded relations are fetched with at most this many calls at once, errgroup hangs on zero and is unbounded below it
+	if cfg.ExpandConcurrency < 1 {
+		panic(fmt.Sprintf("EXPAND_CONCURRENCY must be at least 1, got %d", cfg.ExpandConcurrency))
+	}
+
 	// programmatically set swagger info
 	docs.SwaggerInfo.Title = cfg.App
 	docs.SwaggerInfo.Version = cfg.AppVersion
 
+	shutdownTracing, err := tracing.Init(cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	defer shutdownTracing(context.Background())
+
+	var reporter reporting.Reporter = reporting.Nop{}
+	if cfg.ErrorReportingDSN != "" {
+		reporter, err = reporting.NewSentryReporter(cfg.ErrorReportingDSN, cfg.Environment, cfg.AppVersion)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// metrics are served apart from the public port, only to whoever can reach METRICS_PORT
+	metricsRouter := gin.New()
+	metricsRouter.GET("/metrics", metrics.Handler())
+	go func() {
+		if err := metricsRouter.Run(cfg.MetricsPort); err != nil {
+			panic(err)
+		}
+	}()
+
 	r := gin.New()
-	r.Use(gin.Logger(), gin.Recovery()) // Later they will be replaced by custom Logger and Recovery
+	// X-Forwarded-For is only believed from these, anyone else could claim any client IP
+	if err := r.SetTrustedProxies(splitList(cfg.TrustedProxies)); err != nil {
+		panic(err)
+	}
+	r.Use(middlewares.Recovery(reporter))
+	r.Use(gin.Logger(), middlewares.RequestID(), tracing.Middleware(), metrics.Middleware())
 
 	//template GET method
 	r.GET("/ping", func(c *gin.Context) {
@@ -42,42 +93,146 @@ func main() {
 
 	defer grpcClients.Close()
 
+	sink, err := audit.NewSink(cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	auditSink := audit.NewAsyncSink(sink, cfg.AuditQueueSize)
+	defer auditSink.Close()
+
+	redisPool := redisdb.NewPool(cfg)
+	defer redisPool.Close()
+
+	limiter, err := ratelimit.New(cfg, redisPool)
+	if err != nil {
+		panic(err)
+	}
+	rl := middlewares.RateLimit(limiter)
+
+	preAuthLimiter, err := ratelimit.NewPreAuth(cfg, redisPool)
+	if err != nil {
+		panic(err)
+	}
+
+	cacheStore, err := cache.New(cfg, redisPool)
+	if err != nil {
+		panic(err)
+	}
+	// product responses embed category data, so category changes drop cached products too
+	productCache := middlewares.Cache(cacheStore, "product", cfg.CacheProductTTL)
+	categoryCache := middlewares.Cache(cacheStore, "category", cfg.CacheCategoryTTL)
+	invalidateProduct := middlewares.Invalidate(cacheStore, "product")
+	invalidateCategory := middlewares.Invalidate(cacheStore, "category", "product")
+
+	carts, err := cart.New(cfg, redisPool)
+	if err != nil {
+		panic(err)
+	}
+
+	cursorSecret := cfg.CursorSecret
+	if cursorSecret == "" {
+		// cursors issued by one instance won't be accepted by another or after a restart
+		if cfg.Environment != "development" {
+			panic("CURSOR_SECRET must be set outside development")
+		}
+		log.Println("CURSOR_SECRET is not set, using a random one")
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			panic(err)
+		}
+		cursorSecret = hex.EncodeToString(b)
+	}
+
+	rates, err := money.ParseRates(cfg.ExchangeRates)
+	if err != nil {
+		panic(err)
+	}
+	if !rates.Has(cfg.BaseCurrency) {
+		panic(fmt.Sprintf("EXCHANGE_RATES has no rate for BASE_CURRENCY %s", cfg.BaseCurrency))
+	}
+
 	h := handlers.Handler{
 		GrpcClients: grpcClients,
+		AuditSink:   auditSink,
+		Cursors:     cursor.NewSigner(cursorSecret, cfg.CursorTTL),
+		Cfg:         cfg,
+		Rates:       rates,
+		Carts:       carts,
 	}
 
+	// routes with the id in the body, kept until the sunset for clients of the id-in-path ones
+	deprecated := func(successor string) gin.HandlerFunc {
+		return middlewares.Deprecated(cfg.LegacyRoutesDeprecation, cfg.LegacyRoutesSunset, successor)
+	}
+
+	// checkout is prioritized once AuthMiddleware has verified the user, so it is shed again after it
+	shed := middlewares.LoadShed(loadshed.New(cfg), "POST /v1/order", "POST /v1/cart/checkout")
+
 	v1 := r.Group("/v1")
 	{
 		v1.Use(MyCORSMiddleware())
-		v1.POST("/login", h.Login)
-
-		v1.POST("/order", h.AuthMiddleware("*"), h.CreateOrder)
-		v1.GET("/order", h.AuthMiddleware("*"), h.GetOrderList)
-		v1.GET("/order/:id", h.AuthMiddleware("*"), h.GetOrderById)
-
-		v1.POST("/category", h.AuthMiddleware("*"), h.CreateCategory)
-		v1.GET("/category/:id", h.AuthMiddleware("*"), h.GetCategoryById)
-		v1.GET("/category", h.AuthMiddleware("*"), h.GetCategoryList)
-		v1.PUT("/category", h.AuthMiddleware("*"), h.UpdateCategory)
-		v1.DELETE("/category/:id", h.AuthMiddleware("ADMIN"), h.DeleteCategory)
-
-		v1.POST("/product", h.AuthMiddleware("*"), h.CreateProduct)
-		v1.GET("/product/:id", h.AuthMiddleware("*"), h.GetProductById)
-		v1.GET("/product", h.AuthMiddleware("*"), h.GetProductList)
-		v1.PUT("/product", h.AuthMiddleware("*"), h.UpdateProduct)
-		v1.DELETE("/product/:id", h.AuthMiddleware("ADMIN"), h.DeleteProduct)
-
-		v1.POST("/user", h.CreateUser)
-		v1.GET("/user/:id", h.GetUserById)
-		v1.GET("/user", h.GetUserList)
-		v1.PUT("/user", h.UpdateUser)
-		v1.DELETE("/user/:id", h.DeleteUser)
+		v1.Use(middlewares.APIKey(splitList(cfg.APIKeys)))
+		v1.Use(middlewares.PreAuthRateLimit(preAuthLimiter))
+		v1.Use(shed)
+		v1.Use(middlewares.Audit(auditSink, "/v1/login"))
+		v1.Use(middlewares.ETag())
+		v1.Use(h.DisplayCurrency())
+		v1.POST("/login", rl, h.Login)
+
+		v1.GET("/audit", h.AuthMiddleware("ADMIN"), rl, h.GetAuditEntryList)
+
+		v1.POST("/order", h.AuthMiddleware("*"), shed, rl, h.CreateOrder)
+		v1.GET("/order", h.AuthMiddleware("*"), rl, h.GetOrderList)
+		v1.GET("/order/:id", h.AuthMiddleware("*"), rl, h.GetOrderById)
+
+		v1.GET("/cart", h.AuthMiddleware("*"), rl, h.GetCart)
+		v1.DELETE("/cart", h.AuthMiddleware("*"), rl, h.ClearCart)
+		v1.POST("/cart/items", h.AuthMiddleware("*"), rl, h.AddCartItem)
+		v1.PUT("/cart/items/:product_id", h.AuthMiddleware("*"), rl, h.UpdateCartItem)
+		v1.DELETE("/cart/items/:product_id", h.AuthMiddleware("*"), rl, h.RemoveCartItem)
+		v1.POST("/cart/checkout", h.AuthMiddleware("*"), shed, rl, h.CheckoutCart)
+
+		v1.POST("/category", h.AuthMiddleware("*"), rl, invalidateCategory, h.CreateCategory)
+		v1.GET("/category/:id", h.AuthMiddleware("*"), rl, categoryCache, h.GetCategoryById)
+		v1.GET("/category", h.AuthMiddleware("*"), rl, categoryCache, h.GetCategoryList)
+		v1.PUT("/category/:id", h.AuthMiddleware("*"), rl, invalidateCategory, h.UpdateCategory)
+		v1.PUT("/category", deprecated("/v1/category/{id}"), h.AuthMiddleware("*"), rl, invalidateCategory, h.UpdateCategoryLegacy)
+		v1.PATCH("/category/:id", h.AuthMiddleware("*"), rl, invalidateCategory, h.PatchCategory)
+		v1.DELETE("/category/:id", h.AuthMiddleware("ADMIN"), rl, invalidateCategory, h.DeleteCategory)
+
+		v1.POST("/product", h.AuthMiddleware("*"), rl, invalidateProduct, h.CreateProduct)
+		v1.GET("/product/:id", h.AuthMiddleware("*"), rl, productCache, h.GetProductById)
+		v1.GET("/product", h.AuthMiddleware("*"), rl, productCache, h.GetProductList)
+		v1.PUT("/product/:id", h.AuthMiddleware("*"), rl, invalidateProduct, h.UpdateProduct)
+		v1.PUT("/product", deprecated("/v1/product/{id}"), h.AuthMiddleware("*"), rl, invalidateProduct, h.UpdateProductLegacy)
+		v1.PATCH("/product/:id", h.AuthMiddleware("*"), rl, invalidateProduct, h.PatchProduct)
+		v1.DELETE("/product/:id", h.AuthMiddleware("ADMIN"), rl, invalidateProduct, h.DeleteProduct)
+
+		v1.POST("/user", rl, h.CreateUser)
+		v1.GET("/user/:id", rl, h.GetUserById)
+		v1.GET("/user", rl, h.GetUserList)
+		v1.PUT("/user/:id", rl, h.UpdateUser)
+		v1.PUT("/user", deprecated("/v1/user/{id}"), rl, h.UpdateUserLegacy)
+		v1.PATCH("/user/:id", rl, h.PatchUser)
+		v1.DELETE("/user/:id", rl, h.DeleteUser)
 	}
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	r.Run(cfg.HTTPPort) // listen and serve on 0.0.0.0:8080 (for windows "localhost:8080")
 }
 
+// splitList parses a comma separated config value, an empty one yields no items
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // MyCORSMiddleware ...
 func MyCORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
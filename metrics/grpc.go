@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryClientInterceptor records count and latency of every outgoing unary gRPC call
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		service, name := SplitMethodName(method)
+
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		GrpcClientRequestDuration.WithLabelValues(service, name).Observe(time.Since(start).Seconds())
+		GrpcClientRequestsTotal.WithLabelValues(service, name, status.Code(err).String()).Inc()
+
+		return err
+	}
+}
+
+// SplitMethodName splits a full gRPC method name ("/OrderService/GetOrderList") into service and method
+func SplitMethodName(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.Index(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that did not match any registered route,
+// so that scanners hitting random paths can't blow up label cardinality
+const unmatchedRoute = "unmatched"
+
+// Middleware records request count, latency and in-flight requests per route template
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := c.Request.Method
+
+		inFlight := HTTPRequestsInFlight.WithLabelValues(method, route)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		c.Next()
+
+		HTTPRequestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+		HTTPRequestsTotal.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+	}
+}
@@ -0,0 +1,162 @@
+package metrics
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "api_gateway"
+
+var (
+	// HTTPRequestsTotal counts handled HTTP requests by route template, method and status code
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Total number of HTTP requests handled by the gateway.",
+	}, []string{"method", "route", "code"})
+
+	// HTTPRequestDuration observes HTTP request latency by route template and method
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency in seconds.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// HTTPRequestsInFlight tracks requests currently being served by route template and method
+	HTTPRequestsInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "Number of HTTP requests currently being served.",
+	}, []string{"method", "route"})
+
+	// HTTPPanicsTotal counts panics recovered while serving a route
+	HTTPPanicsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "panics_total",
+		Help:      "Total number of panics recovered while handling HTTP requests.",
+	}, []string{"method", "route"})
+
+	// GrpcClientRequestsTotal counts outgoing gRPC calls by service, method and status code
+	GrpcClientRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc_client",
+		Name:      "requests_total",
+		Help:      "Total number of gRPC calls made to backend services.",
+	}, []string{"service", "method", "code"})
+
+	// GrpcClientRequestDuration observes outgoing gRPC call latency by service and method
+	GrpcClientRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc_client",
+		Name:      "request_duration_seconds",
+		Help:      "gRPC call latency in seconds.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"service", "method"})
+
+	// GrpcClientCoalescibleTotal counts calls to idempotent methods eligible for coalescing
+	GrpcClientCoalescibleTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc_client",
+		Name:      "coalescible_requests_total",
+		Help:      "Total number of idempotent gRPC calls eligible for coalescing.",
+	}, []string{"service", "method"})
+
+	// GrpcClientCoalescedTotal counts calls answered by another caller's in-flight call,
+	// the dedupe ratio is coalesced / coalescible
+	GrpcClientCoalescedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc_client",
+		Name:      "coalesced_requests_total",
+		Help:      "Total number of gRPC calls served by an identical in-flight call.",
+	}, []string{"service", "method"})
+
+	// BulkheadInFlight tracks concurrent calls holding a bulkhead slot per backend
+	BulkheadInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "grpc_client",
+		Name:      "bulkhead_in_flight",
+		Help:      "Number of concurrent gRPC calls holding a bulkhead slot.",
+	}, []string{"backend"})
+
+	// BulkheadRejectedTotal counts calls rejected because a backend bulkhead was full
+	BulkheadRejectedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc_client",
+		Name:      "bulkhead_rejected_total",
+		Help:      "Total number of gRPC calls rejected by a full bulkhead.",
+	}, []string{"backend"})
+
+	// LoadShedLimit reports the current adaptive concurrency limit
+	LoadShedLimit = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "load_shed",
+		Name:      "limit",
+		Help:      "Current adaptive concurrency limit.",
+	})
+
+	// LoadShedInFlight reports requests admitted by the adaptive limiter
+	LoadShedInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "load_shed",
+		Name:      "in_flight",
+		Help:      "Number of requests admitted by the adaptive limiter.",
+	})
+
+	// LoadShedRejectedTotal counts requests shed by priority
+	LoadShedRejectedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "load_shed",
+		Name:      "rejected_total",
+		Help:      "Total number of requests rejected by the adaptive limiter.",
+	}, []string{"priority"})
+
+	// AuditEntriesDroppedTotal counts audit entries which never reached the sink by reason
+	AuditEntriesDroppedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "audit",
+		Name:      "entries_dropped_total",
+		Help:      "Total number of audit entries dropped before reaching the sink.",
+	}, []string{"reason"})
+
+	// AuthDecisionsTotal counts AuthMiddleware outcomes by decision and required user type
+	AuthDecisionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "decisions_total",
+		Help:      "Total number of authorization decisions.",
+	}, []string{"decision", "user_type"})
+
+	// CircuitBreakerState reports the breaker state per backend service (0 closed, 1 half-open, 2 open)
+	CircuitBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "grpc_client",
+		Name:      "circuit_breaker_state",
+		Help:      "Circuit breaker state per backend service: 0 closed, 1 half-open, 2 open.",
+	}, []string{"service"})
+)
+
+// Auth decisions
+const (
+	AuthAllowed      = "allowed"
+	AuthUnauthorized = "unauthorized"
+	AuthForbidden    = "forbidden"
+	AuthError        = "error"
+)
+
+// Reasons for dropping audit entries
+const (
+	AuditQueueFull   = "queue_full"
+	AuditWriteFailed = "write_failed"
+)
+
+// Handler exposes the registered collectors in the Prometheus text format
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
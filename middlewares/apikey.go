@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/uacademy/e_commerce/api_gateway/models"
+)
+
+// APIKeyHeader identifies API clients
+const APIKeyHeader = "X-API-Key"
+
+// APIKey recognizes API clients by one of the configured keys in X-API-Key and stores the id of the client,
+// derived from its key, under models.APIClientKey. Unknown keys are ignored, the request goes on as anonymous.
+func APIKey(keys []string) gin.HandlerFunc {
+	known := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		known[apiClientID(key)] = true
+	}
+
+	return func(c *gin.Context) {
+		if key := c.GetHeader(APIKeyHeader); key != "" {
+			if id := apiClientID(key); known[id] {
+				c.Set(models.APIClientKey, id)
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// apiClientID keeps raw keys out of stores and logs
+func apiClientID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
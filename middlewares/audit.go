@@ -0,0 +1,245 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/uacademy/e_commerce/api_gateway/audit"
+	"github.com/uacademy/e_commerce/api_gateway/models"
+	ecom "github.com/uacademy/e_commerce/api_gateway/proto-gen/e_commerce"
+)
+
+// auditBodyLimit bounds how much of a request or response body is kept for the audit entry
+const auditBodyLimit = 64 << 10
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are redacted from recorded changes wherever they appear
+var sensitiveKeys = []string{"password", "token", "secret", "authorization"}
+
+// Audit records every mutating request (POST, PUT, PATCH, DELETE) to sink.
+// Routes listed in skip, such as login, are not recorded.
+func Audit(sink audit.Sink, skip ...string) gin.HandlerFunc {
+	skipped := make(map[string]bool, len(skip))
+	for _, route := range skip {
+		skipped[route] = true
+	}
+
+	return func(c *gin.Context) {
+		if !isMutating(c.Request.Method) || skipped[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		var requestBody []byte
+		if c.Request.Body != nil {
+			requestBody, _ = io.ReadAll(io.LimitReader(c.Request.Body, auditBodyLimit))
+			c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(requestBody), c.Request.Body))
+		}
+
+		writer := newBodyWriter(c.Writer, auditBodyLimit)
+		c.Writer = writer
+
+		c.Next()
+
+		status := c.Writer.Status()
+		outcome := audit.OutcomeSuccess
+		if status >= http.StatusBadRequest {
+			outcome = audit.OutcomeFailure
+		}
+
+		var changes map[string]models.AuditChange
+		if outcome == audit.OutcomeSuccess {
+			changes = auditChanges(c, writer.body.Bytes())
+		}
+
+		entry := models.AuditEntry{
+			Time:       time.Now().UTC(),
+			RequestId:  GetRequestID(c),
+			Method:     c.Request.Method,
+			Route:      c.FullPath(),
+			Resource:   resourceName(c.FullPath()),
+			ResourceId: resourceID(c, decodeObject(requestBody), writer.body.Bytes()),
+			SourceIP:   c.ClientIP(),
+			Status:     status,
+			Outcome:    outcome,
+			Changes:    changes,
+		}
+
+		if v, ok := c.Get(models.AuthUserKey); ok {
+			if user, ok := v.(*ecom.User); ok && user != nil {
+				entry.UserId = user.Id
+				entry.Username = user.Username
+				entry.UserType = user.UserType
+			}
+		}
+
+		if err := sink.Write(c.Request.Context(), entry); err != nil {
+			log.Printf("audit: failed to write entry: %v", err)
+		}
+	}
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// resourceName turns a route template such as /v1/product/:id into "product"
+func resourceName(route string) string {
+	parts := strings.Split(strings.Trim(route, "/"), "/")
+	if len(parts) > 1 {
+		return parts[1]
+	}
+	return parts[0]
+}
+
+// resourceID looks for the affected id in the path, then the request body, then the response data
+func resourceID(c *gin.Context, requestBody map[string]interface{}, responseBody []byte) string {
+	if id := c.Param("id"); id != "" {
+		return id
+	}
+
+	if id, ok := requestBody["id"].(string); ok && id != "" {
+		return id
+	}
+
+	var response struct {
+		Data struct {
+			Id string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(responseBody, &response); err == nil {
+		return response.Data.Id
+	}
+
+	return ""
+}
+
+// auditChanges compares the fields of the resource before the request, as recorded by the handler,
+// with the ones after it, which are the data of the response unless the handler recorded them too.
+// Only changed fields are kept and sensitive values are redacted on both sides.
+func auditChanges(c *gin.Context, responseBody []byte) map[string]models.AuditChange {
+	before, _ := c.Get(models.AuditBeforeKey)
+
+	after, ok := c.Get(models.AuditAfterKey)
+	if !ok {
+		var response struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(responseBody, &response); err == nil {
+			after = response.Data
+		}
+	}
+
+	beforeFields, afterFields := normalize(before), normalize(after)
+
+	changes := make(map[string]models.AuditChange)
+	for key, value := range beforeFields {
+		if !reflect.DeepEqual(value, afterFields[key]) {
+			changes[key] = redactChange(key, value, afterFields[key])
+		}
+	}
+	for key, value := range afterFields {
+		if _, ok := beforeFields[key]; !ok {
+			changes[key] = redactChange(key, nil, value)
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+// normalize turns a recorded state into the map it is rendered as, so that both sides compare equal
+// when their JSON does
+func normalize(state interface{}) map[string]interface{} {
+	var body []byte
+	switch v := state.(type) {
+	case nil:
+		return nil
+	case json.RawMessage:
+		body = v
+	default:
+		body, _ = json.Marshal(v)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		return nil
+	}
+
+	return object
+}
+
+func redactChange(key string, before, after interface{}) models.AuditChange {
+	return models.AuditChange{
+		Before: redactValue(key, before),
+		After:  redactValue(key, after),
+	}
+}
+
+func redactValue(key string, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	if isSensitive(key) {
+		return redacted
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		redact(v)
+	case []interface{}:
+		for _, item := range v {
+			if nested, ok := item.(map[string]interface{}); ok {
+				redact(nested)
+			}
+		}
+	}
+	return value
+}
+
+func decodeObject(body []byte) map[string]interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(body, &object); err != nil {
+		return nil
+	}
+
+	return object
+}
+
+func redact(object map[string]interface{}) {
+	for key, value := range object {
+		object[key] = redactValue(key, value)
+	}
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package middlewares
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/uacademy/e_commerce/api_gateway/cache"
+)
+
+// CacheHeader tells clients whether the response was served from the cache
+const CacheHeader = "X-Cache"
+
+// cacheBodyLimit bounds the size of a response that is worth caching
+const cacheBodyLimit = 1 << 20
+
+// cachedHeaders are replayed from the cache along with the body
+var cachedHeaders = []string{"Content-Type", "ETag", "Link", "X-Total-Count"}
+
+// Cache serves GET responses of a route from store for ttl.
+// Entries are grouped under namespace so that Invalidate can drop them together.
+// Keys carry the generation of the namespace, so a response rendered before a write
+// but stored after its invalidation is never served.
+func Cache(store cache.Store, namespace string, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		generation, err := store.Generation(c.Request.Context(), namespace)
+		if err != nil {
+			log.Printf("cache: %v", err)
+			c.Next()
+			return
+		}
+
+		key := namespace + ":" + strconv.FormatInt(generation, 10) + ":" +
+			c.Request.URL.Path + "?" + c.Request.URL.Query().Encode()
+
+		entry, ok, err := store.Get(c.Request.Context(), key)
+		if err != nil {
+			log.Printf("cache: %v", err)
+		}
+		if ok {
+			for name, values := range entry.Header {
+				for _, value := range values {
+					c.Writer.Header().Add(name, value)
+				}
+			}
+			c.Header(CacheHeader, "HIT")
+			c.Data(entry.Status, entry.Header.Get("Content-Type"), entry.Body)
+			c.Abort()
+			return
+		}
+
+		c.Header(CacheHeader, "MISS")
+
+		writer := newBodyWriter(c.Writer, cacheBodyLimit)
+		c.Writer = writer
+
+		c.Next()
+
+		if c.Writer.Status() != http.StatusOK || writer.truncated {
+			return
+		}
+
+		entry = cache.Entry{
+			Status: http.StatusOK,
+			Header: http.Header{},
+			Body:   writer.body.Bytes(),
+		}
+		for _, name := range cachedHeaders {
+			if value := c.Writer.Header().Get(name); value != "" {
+				entry.Header.Set(name, value)
+			}
+		}
+
+		if err := store.Set(c.Request.Context(), key, entry, ttl); err != nil {
+			log.Printf("cache: %v", err)
+		}
+	}
+}
+
+// Invalidate drops every cached entry of namespaces once the request has succeeded.
+// The generation is bumped first, so that responses still being rendered are stored under keys nobody reads.
+func Invalidate(store cache.Store, namespaces ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+
+		for _, namespace := range namespaces {
+			if err := store.Bump(c.Request.Context(), namespace); err != nil {
+				log.Printf("cache: %v", err)
+			}
+			if err := store.DeletePrefix(c.Request.Context(), namespace+":"); err != nil {
+				log.Printf("cache: %v", err)
+			}
+		}
+	}
+}
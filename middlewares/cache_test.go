@@ -0,0 +1,136 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/uacademy/e_commerce/api_gateway/cache"
+)
+
+func TestCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := cache.NewLRUStore(10)
+	title := "A"
+	calls := 0
+
+	r := gin.New()
+	r.GET("/v1/product/:id", Cache(store, "product", time.Minute), func(c *gin.Context) {
+		calls++
+		if c.Query("fail") != "" {
+			c.String(http.StatusInternalServerError, "failed")
+			return
+		}
+		c.Header("ETag", `"`+title+`"`)
+		c.String(http.StatusOK, title)
+	})
+	r.PUT("/v1/product/:id", Invalidate(store, "product"), func(c *gin.Context) {
+		title = c.Query("title")
+		c.Status(http.StatusOK)
+	})
+	r.PUT("/v1/category/:id", Invalidate(store, "product"), func(c *gin.Context) {
+		c.Status(http.StatusBadRequest)
+	})
+
+	tests := []struct {
+		method, target string
+		cache          string
+		body           string
+		calls          int
+	}{
+		{method: http.MethodGet, target: "/v1/product/1", cache: "MISS", body: "A", calls: 1},
+		{method: http.MethodGet, target: "/v1/product/1", cache: "HIT", body: "A", calls: 1},
+		// the same query in another order is the same entry
+		{method: http.MethodGet, target: "/v1/product/1?b=2&a=1", cache: "MISS", body: "A", calls: 2},
+		{method: http.MethodGet, target: "/v1/product/1?a=1&b=2", cache: "HIT", body: "A", calls: 2},
+		// errors are not cached
+		{method: http.MethodGet, target: "/v1/product/1?fail=1", cache: "MISS", body: "failed", calls: 3},
+		{method: http.MethodGet, target: "/v1/product/1?fail=1", cache: "MISS", body: "failed", calls: 4},
+		// a failed write doesn't invalidate
+		{method: http.MethodPut, target: "/v1/category/1", calls: 4},
+		{method: http.MethodGet, target: "/v1/product/1", cache: "HIT", body: "A", calls: 4},
+		{method: http.MethodPut, target: "/v1/product/1?title=B", calls: 4},
+		{method: http.MethodGet, target: "/v1/product/1", cache: "MISS", body: "B", calls: 5},
+		{method: http.MethodGet, target: "/v1/product/1", cache: "HIT", body: "B", calls: 5},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, nil))
+
+		if got := w.Header().Get(CacheHeader); got != tt.cache {
+			t.Errorf("%s %s X-Cache = %q, want %q", tt.method, tt.target, got, tt.cache)
+		}
+		if tt.method == http.MethodGet && w.Body.String() != tt.body {
+			t.Errorf("%s %s body = %q, want %q", tt.method, tt.target, w.Body.String(), tt.body)
+		}
+		if tt.cache == "HIT" && w.Header().Get("ETag") != `"`+tt.body+`"` {
+			t.Errorf("%s %s ETag = %q, want it replayed from the cache", tt.method, tt.target, w.Header().Get("ETag"))
+		}
+		if calls != tt.calls {
+			t.Errorf("%s %s handler calls = %d, want %d", tt.method, tt.target, calls, tt.calls)
+		}
+	}
+}
+
+func TestCacheMissRacingInvalidate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := cache.NewLRUStore(10)
+	title := "A"
+	slow := int32(1)
+	rendered := make(chan struct{})
+	written := make(chan struct{})
+
+	r := gin.New()
+	r.GET("/v1/product/:id", Cache(store, "product", time.Minute), func(c *gin.Context) {
+		body := title
+		if atomic.CompareAndSwapInt32(&slow, 1, 0) {
+			// the response is rendered before the write and stored after its invalidation
+			close(rendered)
+			<-written
+		}
+		c.String(http.StatusOK, body)
+	})
+	r.PUT("/v1/product/:id", Invalidate(store, "product"), func(c *gin.Context) {
+		title = "B"
+		c.Status(http.StatusOK)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/product/1", nil))
+	}()
+
+	<-rendered
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/v1/product/1", nil))
+	close(written)
+	<-done
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/product/1", nil))
+	if w.Header().Get(CacheHeader) != "MISS" || w.Body.String() != "B" {
+		t.Errorf("response = %s %q, want the stale one not to be served", w.Header().Get(CacheHeader), w.Body.String())
+	}
+}
+
+func TestCacheSkipsOtherMethods(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.POST("/v1/product", Cache(cache.NewLRUStore(10), "product", time.Minute), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/product", nil))
+	if w.Header().Get(CacheHeader) != "" {
+		t.Errorf("X-Cache = %q on a POST", w.Header().Get(CacheHeader))
+	}
+}
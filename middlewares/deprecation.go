@@ -0,0 +1,25 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Deprecated marks the responses of a route that is being replaced by successor, e.g. "/v1/product/{id}",
+// with the Deprecation (RFC 9745) and Sunset (RFC 8594) headers and a link to the successor
+func Deprecated(deprecation, sunset time.Time, successor string) gin.HandlerFunc {
+	deprecationHeader := fmt.Sprintf("@%d", deprecation.Unix())
+	sunsetHeader := sunset.UTC().Format(http.TimeFormat)
+	linkHeader := fmt.Sprintf(`<%s>; rel="successor-version"`, successor)
+
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecationHeader)
+		c.Header("Sunset", sunsetHeader)
+		c.Header("Link", linkHeader)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,87 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETag adds a strong ETag to successful GET responses and answers
+// If-None-Match with 304 Not Modified when the client's copy is current.
+// Handlers may set their own ETag, otherwise it is a hash of the body.
+func ETag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := newBufferedWriter(original)
+		c.Writer = writer
+		// a panic must reach Recovery with the real writer in place
+		defer func() { c.Writer = original }()
+
+		c.Next()
+
+		c.Writer = original
+
+		if writer.Status() != http.StatusOK {
+			writer.flush()
+			return
+		}
+
+		etag := original.Header().Get("ETag")
+		if etag == "" {
+			etag = BodyETag(writer.body.Bytes())
+			original.Header().Set("ETag", etag)
+		}
+
+		if ETagMatches(c.GetHeader("If-None-Match"), etag, true) {
+			original.Header().Del("Content-Type")
+			original.Header().Del("Content-Length")
+			original.WriteHeader(http.StatusNotModified)
+			original.WriteHeaderNow()
+			return
+		}
+
+		writer.flush()
+	}
+}
+
+// BodyETag returns a strong ETag for a response body
+func BodyETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// ETagMatches reports whether an If-Match or If-None-Match header value matches etag.
+// If-None-Match uses the weak comparison (W/ prefixes ignored), If-Match the strong one.
+func ETagMatches(header, etag string, weak bool) bool {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+
+	if weak {
+		etag = strings.TrimPrefix(etag, "W/")
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
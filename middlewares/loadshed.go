@@ -0,0 +1,67 @@
+package middlewares
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/uacademy/e_commerce/api_gateway/loadshed"
+	"github.com/uacademy/e_commerce/api_gateway/metrics"
+	"github.com/uacademy/e_commerce/api_gateway/models"
+)
+
+// loadShedKey marks requests LoadShed has already admitted
+const loadShedKey = "load_shed_admitted"
+
+// LoadShed rejects requests with 503 once the adaptive limit is reached.
+// Requests to priorityRoutes ("METHOD route") by a verified user may use the whole limit,
+// everything else only a share of it. Priority routes must register LoadShed again after AuthMiddleware:
+// the group-level instance leaves their requests to that one, so that the decision is made on a verified
+// user rather than on the mere presence of a token.
+func LoadShed(limiter *loadshed.Limiter, priorityRoutes ...string) gin.HandlerFunc {
+	priority := make(map[string]bool, len(priorityRoutes))
+	for _, route := range priorityRoutes {
+		priority[route] = true
+	}
+
+	return func(c *gin.Context) {
+		if c.GetBool(loadShedKey) {
+			c.Next()
+			return
+		}
+
+		level, label := loadshed.PriorityLow, "low"
+		if priority[c.Request.Method+" "+c.FullPath()] {
+			if _, ok := c.Get(models.AuthUserKey); !ok {
+				c.Next()
+				return
+			}
+			level, label = loadshed.PriorityHigh, "high"
+		}
+		c.Set(loadShedKey, true)
+
+		release, ok := limiter.Acquire(level)
+		if !ok {
+			metrics.LoadShedRejectedTotal.WithLabelValues(label).Inc()
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, models.JSONError{
+				Error:     "Service Unavailable",
+				RequestId: GetRequestID(c),
+			})
+			return
+		}
+
+		start := time.Now()
+		defer func() {
+			status := c.Writer.Status()
+			release(time.Since(start), status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout)
+
+			limit, inFlight := limiter.Limit()
+			metrics.LoadShedLimit.Set(limit)
+			metrics.LoadShedInFlight.Set(float64(inFlight))
+		}()
+
+		c.Next()
+	}
+}
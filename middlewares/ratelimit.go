@@ -0,0 +1,84 @@
+package middlewares
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/uacademy/e_commerce/api_gateway/models"
+	ecom "github.com/uacademy/e_commerce/api_gateway/proto-gen/e_commerce"
+	"github.com/uacademy/e_commerce/api_gateway/ratelimit"
+)
+
+// RateLimit throttles requests per API client, authenticated user or client IP, in that order.
+// Place it after APIKey and AuthMiddleware so that only verified identities get a bucket of their own,
+// anything a client merely claims falls back to its IP.
+func RateLimit(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return rateLimit(limiter, rateLimitIdentity)
+}
+
+// PreAuthRateLimit throttles requests per client IP before AuthMiddleware runs,
+// so that floods of unauthenticated requests don't reach the auth service.
+// Place it after APIKey: API clients are verified locally and only throttled by RateLimit.
+func PreAuthRateLimit(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	limit := rateLimit(limiter, func(c *gin.Context) string {
+		return "ip:" + c.ClientIP()
+	})
+
+	return func(c *gin.Context) {
+		if c.GetString(models.APIClientKey) != "" {
+			c.Next()
+			return
+		}
+		limit(c)
+	}
+}
+
+func rateLimit(limiter *ratelimit.Limiter, identity func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := limiter.Take(c.Request.Context(), c.Request.Method, c.FullPath(), identity(c))
+		if err != nil {
+			// a broken store must not take the whole gateway down with it
+			log.Printf("rate limit: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, models.JSONError{
+				Error:     "Too Many Requests",
+				RequestId: GetRequestID(c),
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func rateLimitIdentity(c *gin.Context) string {
+	if id := c.GetString(models.APIClientKey); id != "" {
+		return "key:" + id
+	}
+
+	if v, ok := c.Get(models.AuthUserKey); ok {
+		if user, ok := v.(*ecom.User); ok && user != nil && user.Id != "" {
+			return "user:" + user.Id
+		}
+	}
+
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,107 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/uacademy/e_commerce/api_gateway/models"
+	ecom "github.com/uacademy/e_commerce/api_gateway/proto-gen/e_commerce"
+	"github.com/uacademy/e_commerce/api_gateway/ratelimit"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(10), ratelimit.Rate{Limit: 2, Period: time.Minute}, nil)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if id := c.GetHeader("X-User"); id != "" {
+			c.Set(models.AuthUserKey, &ecom.User{Id: id})
+		}
+	})
+	r.GET("/v1/product", RateLimit(limiter), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		user       string
+		code       int
+		remaining  string
+		retryAfter string
+	}{
+		{code: http.StatusOK, remaining: "1"},
+		{code: http.StatusOK, remaining: "0"},
+		{code: http.StatusTooManyRequests, remaining: "0", retryAfter: "30"},
+		// an authenticated user has a bucket of their own, not the one of their IP
+		{user: "u1", code: http.StatusOK, remaining: "1"},
+	}
+
+	for i, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/v1/product", nil)
+		if tt.user != "" {
+			req.Header.Set("X-User", tt.user)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.code {
+			t.Errorf("request %d status = %d, want %d", i, w.Code, tt.code)
+		}
+		if got := w.Header().Get("RateLimit-Limit"); got != "2" {
+			t.Errorf("request %d RateLimit-Limit = %q, want 2", i, got)
+		}
+		if got := w.Header().Get("RateLimit-Remaining"); got != tt.remaining {
+			t.Errorf("request %d RateLimit-Remaining = %q, want %q", i, got, tt.remaining)
+		}
+		if w.Header().Get("RateLimit-Reset") == "" {
+			t.Errorf("request %d has no RateLimit-Reset", i)
+		}
+		if got := w.Header().Get("Retry-After"); got != tt.retryAfter {
+			t.Errorf("request %d Retry-After = %q, want %q", i, got, tt.retryAfter)
+		}
+	}
+}
+
+func TestPreAuthRateLimitSkipsAPIClients(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(10), ratelimit.Rate{Limit: 1, Period: time.Minute}, nil)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if id := c.GetHeader("X-Client"); id != "" {
+			c.Set(models.APIClientKey, id)
+		}
+	}, PreAuthRateLimit(limiter))
+	r.GET("/v1/product", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		client string
+		code   int
+	}{
+		{code: http.StatusOK},
+		{code: http.StatusTooManyRequests},
+		{client: "shop", code: http.StatusOK},
+		{client: "shop", code: http.StatusOK},
+	}
+
+	for i, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/v1/product", nil)
+		if tt.client != "" {
+			req.Header.Set("X-Client", tt.client)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.code {
+			t.Errorf("request %d status = %d, want %d", i, w.Code, tt.code)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/uacademy/e_commerce/api_gateway/metrics"
+	"github.com/uacademy/e_commerce/api_gateway/models"
+	"github.com/uacademy/e_commerce/api_gateway/reporting"
+)
+
+// reportTimeout bounds how long a single event may take to reach the reporter
+const reportTimeout = 5 * time.Second
+
+type panicLogEntry struct {
+	Level     string `json:"level"`
+	Msg       string `json:"msg"`
+	Panic     string `json:"panic"`
+	RequestID string `json:"request_id"`
+	Method    string `json:"method"`
+	Route     string `json:"route"`
+	Path      string `json:"path"`
+	Stack     string `json:"stack"`
+	Time      string `json:"time"`
+}
+
+// Recovery converts panics into a 500 JSONError carrying the request id,
+// logs the stack as a single JSON line and forwards the event to reporter.
+// It must be registered first so that panics in the other middlewares are caught too.
+func Recovery(reporter reporting.Reporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				// deliberate abort of the response, net/http handles it quietly
+				panic(rec)
+			}
+
+			route := c.FullPath()
+			event := reporting.Event{
+				Message:   fmt.Sprint(rec),
+				Stack:     string(debug.Stack()),
+				RequestID: GetRequestID(c),
+				Method:    c.Request.Method,
+				Route:     route,
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now(),
+			}
+
+			logPanic(event)
+			metrics.HTTPPanicsTotal.WithLabelValues(c.Request.Method, route).Inc()
+
+			if isBrokenPipe(rec) {
+				// the client is gone, there is nobody to respond to
+				c.Error(rec.(error))
+				c.Abort()
+				return
+			}
+
+			go func() {
+				ctx, cancel := context.WithTimeout(context.Background(), reportTimeout)
+				defer cancel()
+
+				if err := reporter.Report(ctx, event); err != nil {
+					log.Printf("error reporting failed: %v", err)
+				}
+			}()
+
+			if c.Writer.Written() {
+				// part of the response is already out, a JSON body would only corrupt it
+				c.Abort()
+				return
+			}
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.JSONError{
+				Error:     http.StatusText(http.StatusInternalServerError),
+				RequestId: event.RequestID,
+			})
+		}()
+
+		c.Next()
+	}
+}
+
+func logPanic(event reporting.Event) {
+	entry, err := json.Marshal(panicLogEntry{
+		Level:     "error",
+		Msg:       "panic recovered",
+		Panic:     event.Message,
+		RequestID: event.RequestID,
+		Method:    event.Method,
+		Route:     event.Route,
+		Path:      event.Path,
+		Stack:     event.Stack,
+		Time:      event.Timestamp.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		log.Printf("panic recovered: %s\n%s", event.Message, event.Stack)
+		return
+	}
+
+	log.Println(string(entry))
+}
+
+func isBrokenPipe(rec interface{}) bool {
+	err, ok := rec.(error)
+	if !ok {
+		return false
+	}
+
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+
+	var syscallErr *os.SyscallError
+	if errors.As(opErr, &syscallErr) {
+		return errors.Is(syscallErr.Err, syscall.EPIPE) || errors.Is(syscallErr.Err, syscall.ECONNRESET)
+	}
+
+	msg := strings.ToLower(opErr.Error())
+	return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+}
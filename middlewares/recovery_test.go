@@ -0,0 +1,130 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/uacademy/e_commerce/api_gateway/models"
+	"github.com/uacademy/e_commerce/api_gateway/reporting"
+)
+
+// stubReporter hands reported events to the test
+type stubReporter chan reporting.Event
+
+func (r stubReporter) Report(ctx context.Context, event reporting.Event) error {
+	r <- event
+	return nil
+}
+
+func newRecoveryRouter(reporter reporting.Reporter, middlewares ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(Recovery(reporter), RequestID())
+	r.Use(middlewares...)
+	return r
+}
+
+func TestRecovery(t *testing.T) {
+	reporter := make(stubReporter, 1)
+	r := newRecoveryRouter(reporter)
+	r.GET("/v1/product/:id", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/product/7", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", w.Code)
+	}
+
+	var body models.JSONError
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	requestID := w.Header().Get(RequestIDHeader)
+	if body.Error != http.StatusText(http.StatusInternalServerError) || body.RequestId == "" || body.RequestId != requestID {
+		t.Errorf("body = %+v, want the request id %q", body, requestID)
+	}
+
+	select {
+	case event := <-reporter:
+		if event.Message != "boom" || event.Route != "/v1/product/:id" || event.Path != "/v1/product/7" || event.RequestID != requestID {
+			t.Errorf("event = %+v", event)
+		}
+		if event.Stack == "" {
+			t.Error("event has no stack")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("panic was not reported")
+	}
+}
+
+func TestRecoveryInMiddleware(t *testing.T) {
+	reporter := make(stubReporter, 1)
+	r := newRecoveryRouter(reporter, func(c *gin.Context) {
+		panic("middleware")
+	})
+	r.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", w.Code)
+	}
+	select {
+	case <-reporter:
+	case <-time.After(time.Second):
+		t.Fatal("panic was not reported")
+	}
+}
+
+func TestRecoveryAfterWrite(t *testing.T) {
+	reporter := make(stubReporter, 1)
+	r := newRecoveryRouter(reporter)
+	r.GET("/stream", func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stream", nil))
+
+	if w.Code != http.StatusOK || w.Body.String() != "partial" {
+		t.Errorf("response = %d %q, want the part written before the panic", w.Code, w.Body.String())
+	}
+	select {
+	case <-reporter:
+	case <-time.After(time.Second):
+		t.Fatal("panic was not reported")
+	}
+}
+
+func TestRecoveryAbortHandler(t *testing.T) {
+	reporter := make(stubReporter, 1)
+	r := newRecoveryRouter(reporter)
+	r.GET("/abort", func(c *gin.Context) {
+		panic(http.ErrAbortHandler)
+	})
+
+	defer func() {
+		if rec := recover(); rec != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler to be re-panicked", rec)
+		}
+		if len(reporter) != 0 {
+			t.Error("http.ErrAbortHandler was reported")
+		}
+	}()
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+}
@@ -0,0 +1,85 @@
+package middlewares
+
+import (
+	"bytes"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bodyWriter keeps a copy of the response body, up to limit bytes, while writing it through
+type bodyWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func newBodyWriter(w gin.ResponseWriter, limit int) *bodyWriter {
+	return &bodyWriter{ResponseWriter: w, limit: limit}
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyWriter) capture(b []byte) {
+	if w.truncated {
+		return
+	}
+	if w.body.Len()+len(b) > w.limit {
+		w.truncated = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(b)
+}
+
+// bufferedWriter holds back the status line and body so that the response can still be replaced
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body    bytes.Buffer
+	written bool
+}
+
+func newBufferedWriter(w gin.ResponseWriter) *bufferedWriter {
+	return &bufferedWriter{ResponseWriter: w}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.body.Write(b)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.written
+}
+
+func (w *bufferedWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+// flush sends the held back status and body to the underlying writer
+func (w *bufferedWriter) flush() {
+	w.ResponseWriter.WriteHeaderNow()
+	if w.body.Len() > 0 {
+		w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Context keys under which handlers record the state of a resource around a change for its audit entry
+const (
+	AuditBeforeKey = "audit_before"
+	AuditAfterKey  = "audit_after"
+)
+
+type AuditEntry struct {
+	Time       time.Time              `json:"time"`
+	RequestId  string                 `json:"request_id,omitempty"`
+	UserId     string                 `json:"user_id,omitempty"`
+	Username   string                 `json:"username,omitempty"`
+	UserType   string                 `json:"user_type,omitempty"`
+	Method     string                 `json:"method"`
+	Route      string                 `json:"route"`
+	Resource   string                 `json:"resource"`
+	ResourceId string                 `json:"resource_id,omitempty"`
+	SourceIP   string                 `json:"source_ip"`
+	Status     int                    `json:"status"`
+	Outcome    string                 `json:"outcome"`
+	Changes    map[string]AuditChange `json:"changes,omitempty"`
+}
+
+// AuditChange is the value of a field before and after a request, null when the field didn't exist
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+type AuditQuery struct {
+	UserId     string    `form:"user_id"`
+	Resource   string    `form:"resource"`
+	ResourceId string    `form:"resource_id"`
+	From       time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit      int       `form:"limit" binding:"min=1,max=1000"`
+}
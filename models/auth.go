@@ -1,12 +1,18 @@
 package models
 
+// AuthUserKey is the gin context key AuthMiddleware stores the authenticated *ecom.User under
+const AuthUserKey = "auth_user"
+
 // LoginModel ...
 type LoginModel struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Username string `json:"username" binding:"required,max=64"`
+	Password string `json:"password" binding:"required,max=72"`
 }
 
 // TokenResponse ...
 type TokenResponse struct {
 	Token string `json:"token"`
 }
+
+// APIClientKey is the gin context key APIKey stores the id of a recognized API client under
+const APIClientKey = "api_client"
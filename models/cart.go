@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Cart is priced at the current prices of its products, the total is in the base currency
+type Cart struct {
+	User_id    string         `json:"user_id"`
+	Items      []CartItem     `json:"items"`
+	Total      DisplayedMoney `json:"total"`
+	Updated_at time.Time      `json:"updated_at"`
+}
+
+// CartItem is unavailable when its product was removed or has no price, it is left out of the total then
+type CartItem struct {
+	Product_id string          `json:"product_id"`
+	Quantity   int32           `json:"quantity"`
+	Title      string          `json:"title"`
+	Available  bool            `json:"available"`
+	Unit_price *DisplayedMoney `json:"unit_price"`
+	Line_total *DisplayedMoney `json:"line_total"`
+	Added_at   time.Time       `json:"added_at"`
+}
+
+type AddCartItemModel struct {
+	Product_id string `json:"product_id" binding:"required,uuid"`
+	Quantity   int32  `json:"quantity" binding:"required,min=1,max=1000"`
+}
+
+type UpdateCartItemModel struct {
+	Quantity int32 `json:"quantity" binding:"required,min=1,max=1000"`
+}
+
+type CheckoutCartModel struct {
+	User_name    string `json:"user_name" binding:"required,max=255"`
+	User_address string `json:"user_address" binding:"required,max=500"`
+	User_phone   string `json:"user_phone" binding:"required,phone"`
+}
@@ -8,13 +8,14 @@ type Category struct {
 	Created_at    time.Time  `json:"created_at"`
 	Updated_at    *time.Time `json:"updated_at"`
 	Deleted_at    *time.Time `json:"deleted_at"`
+	Version       int64      `json:"version"`
 }
 
 type CreateCategoryModel struct {
-	CategoryTitle string     `json:"category_title"`
+	CategoryTitle string `json:"category_title" binding:"required,max=255"`
 }
 
 type UpdateCategoryModel struct {
-	Id       string `json:"id" binding:"required"`
-	CategoryTitle string     `json:"category_title"`
+	Id            string `json:"id" binding:"required,uuid"`
+	CategoryTitle string `json:"category_title" binding:"required,max=255"`
 }
@@ -6,5 +6,28 @@ type JSONResult struct {
 }
 
 type JSONError struct {
-	Error string `json:"error"`
+	Error     string       `json:"error"`
+	Fields    []FieldError `json:"fields,omitempty"`
+	RequestId string       `json:"request_id,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type JSONListResult struct {
+	Message    string      `json:"message"`
+	Data       interface{} `json:"data"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+type Pagination struct {
+	Total      int    `json:"total"`
+	Offset     int    `json:"offset"`
+	Limit      int    `json:"limit"`
+	Cursor     string `json:"cursor,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Next       string `json:"next,omitempty"`
+	Prev       string `json:"prev,omitempty"`
 }
@@ -0,0 +1,13 @@
+package models
+
+// Money is an amount with its ISO 4217 currency code, e.g. {"amount": "10.50", "currency": "USD"}
+type Money struct {
+	Amount   string `json:"amount" binding:"required,decimal" example:"10.50"`
+	Currency string `json:"currency" binding:"required,iso4217" example:"USD"`
+}
+
+// DisplayedMoney is money in a response, Display holds it converted into the currency the request asked for
+type DisplayedMoney struct {
+	Money
+	Display *Money `json:"display,omitempty"`
+}
@@ -3,30 +3,52 @@ package models
 import "time"
 
 type Order struct {
-	Id           string    `json:"id"`
-	Product_id   string    `json:"product_id"`
-	Quantity     int32     `json:"quantity"`
-	User_name    string    `json:"user_name"`
-	User_address string    `json:"user_address"`
-	User_phone   string    `json:"user_phone"`
-	Created_at   time.Time `json:"created_at"`
+	Id           string         `json:"id"`
+	Product_id   string         `json:"product_id"`
+	Quantity     int32          `json:"quantity"`
+	User_name    string         `json:"user_name"`
+	User_address string         `json:"user_address"`
+	User_phone   string         `json:"user_phone"`
+	Created_at   time.Time      `json:"created_at"`
+	Status       string         `json:"status"`
+	Items        []OrderItem    `json:"items"`
+	Total        DisplayedMoney `json:"total"`
 }
 
+type OrderItem struct {
+	Product_id string         `json:"product_id"`
+	Quantity   int32          `json:"quantity"`
+	Unit_price DisplayedMoney `json:"unit_price"`
+	Line_total DisplayedMoney `json:"line_total"`
+	// embedded with expand=product
+	Product *Product `json:"product,omitempty"`
+}
+
+// CreateOrderModel takes either items or, for a single product, product_id and quantity
 type CreateOrderModel struct {
-	Product_id   string `json:"product_id"`
-	Quantity     int32  `json:"quantity"`
-	User_name    string `json:"user_name"`
-	User_address string `json:"user_address"`
-	User_phone   string `json:"user_phone"`
+	Product_id   string                 `json:"product_id" binding:"required_without=Items,excluded_with=Items,omitempty,uuid"`
+	Quantity     int32                  `json:"quantity" binding:"required_with=Product_id,omitempty,min=1,max=1000"`
+	Items        []CreateOrderItemModel `json:"items" binding:"omitempty,max=50,unique=Product_id,dive"`
+	User_name    string                 `json:"user_name" binding:"required,max=255"`
+	User_address string                 `json:"user_address" binding:"required,max=500"`
+	User_phone   string                 `json:"user_phone" binding:"required,phone"`
+}
+
+type CreateOrderItemModel struct {
+	Product_id string `json:"product_id" binding:"required,uuid"`
+	Quantity   int32  `json:"quantity" binding:"required,min=1,max=1000"`
 }
 
 type PackedOrderModel struct {
-	Id           string    `json:"id"`
-	Product_id   string    `json:"product_id"`
-	Quantity     int32     `json:"quantity"`
-	User_name    string    `json:"user_name"`
-	User_address string    `json:"user_address"`
-	User_phone   string    `json:"user_phone"`
-	Created_at   time.Time `json:"created_at"`
-	Product      Product   `json:"product"`
+	Id           string         `json:"id"`
+	Product_id   string         `json:"product_id"`
+	Quantity     int32          `json:"quantity"`
+	User_name    string         `json:"user_name"`
+	User_address string         `json:"user_address"`
+	User_phone   string         `json:"user_phone"`
+	Created_at   time.Time      `json:"created_at"`
+	Status       string         `json:"status"`
+	Product      Product        `json:"product"`
+	Items        []OrderItem    `json:"items"`
+	Total        DisplayedMoney `json:"total"`
 }
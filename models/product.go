@@ -3,25 +3,35 @@ package models
 import "time"
 
 type Product struct {
-	Id         string     `json:"id"`
-	CategoryId string     `json:"category_id" binding:"required"`
-	Title      string     `json:"title"`
-	Descrip    string     `json:"description"`
-	Price      string     `json:"price"`
-	Created_at time.Time  `json:"created_at"`
-	Updated_at *time.Time `json:"updated_at"`
-	Deleted_at *time.Time `json:"deleted_at"`
+	Id         string         `json:"id"`
+	CategoryId string         `json:"category_id" binding:"required"`
+	Title      string         `json:"title"`
+	Descrip    string         `json:"description"`
+	Price      DisplayedMoney `json:"price"`
+	Created_at time.Time      `json:"created_at"`
+	Updated_at *time.Time     `json:"updated_at"`
+	Deleted_at *time.Time     `json:"deleted_at"`
+	Version    int64          `json:"version"`
 }
 
 type CreateProductModel struct {
-	CategoryId string `json:"category_id" binding:"required"`
-	Title      string `json:"title"`
-	Descrip    string `json:"description"`
-	Price      string `json:"price"`
+	CategoryId string `json:"category_id" binding:"required,uuid"`
+	Title      string `json:"title" binding:"required,max=255"`
+	Descrip    string `json:"description" binding:"max=5000"`
+	Price      Money  `json:"price"`
 }
 
 type UpdateProductModel struct {
-	Id    string `json:"id" binding:"required"`
-	Title string `json:"title"`
-	Price      string `json:"price"`
+	Id         string `json:"id" binding:"required,uuid"`
+	CategoryId string `json:"category_id" binding:"omitempty,uuid"`
+	Title      string `json:"title" binding:"required,max=255"`
+	Descrip    string `json:"description" binding:"max=5000"`
+	Price      Money  `json:"price"`
+}
+
+// LegacyUpdateProductModel is the body of the deprecated PUT /v1/product, which only changes the title and price
+type LegacyUpdateProductModel struct {
+	Id    string `json:"id" binding:"required,uuid"`
+	Title string `json:"title" binding:"required,max=255"`
+	Price Money  `json:"price"`
 }
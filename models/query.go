@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+type ListQuery struct {
+	Offset int    `form:"offset" binding:"min=0"`
+	Limit  int    `form:"limit" binding:"min=1"`
+	Search string `form:"search" binding:"max=255"`
+}
+
+type ProductListQuery struct {
+	ListQuery
+	Cursor       string    `form:"cursor"`
+	Sort         []string  `form:"sort" binding:"max=3,unique,dive,oneof=price -price title -title created_at -created_at updated_at -updated_at"`
+	CategoryId   string    `form:"category_id" binding:"omitempty,uuid"`
+	PriceMin     string    `form:"price_min" binding:"omitempty,decimal"`
+	PriceMax     string    `form:"price_max" binding:"omitempty,decimal"`
+	CreatedAfter time.Time `form:"created_after"`
+}
+
+type OrderListQuery struct {
+	ListQuery
+	Cursor      string    `form:"cursor"`
+	Sort        []string  `form:"sort" binding:"max=3,unique,dive,oneof=created_at -created_at quantity -quantity"`
+	ProductId   string    `form:"product_id" binding:"omitempty,uuid"`
+	UserPhone   string    `form:"user_phone" binding:"omitempty,phone"`
+	CreatedFrom time.Time `form:"created_from"`
+	CreatedTo   time.Time `form:"created_to"`
+	Status      []string  `form:"status" binding:"unique,dive,oneof=pending paid shipped delivered cancelled"`
+}
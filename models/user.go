@@ -13,12 +13,12 @@ type User struct {
 }
 
 type CreateUserModel struct {
-	Username   string     `json:"username"`
-	Password   string     `json:"password"`
-	User_type  string     `json:"user_type"`
+	Username  string `json:"username" binding:"required,min=3,max=64"`
+	Password  string `json:"password" binding:"required,min=8,max=72"`
+	User_type string `json:"user_type" binding:"required,max=32"`
 }
 
 type UpdateUserModel struct {
-	Id            string `json:"id" binding:"required"`
-	Password   string     `json:"password"`
+	Id       string `json:"id" binding:"required,uuid"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
 }
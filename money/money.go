@@ -0,0 +1,183 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrPrecision is returned for amounts with more decimal places than their currency has minor units
+	ErrPrecision = errors.New("too many decimal places for the currency")
+	// ErrRange is returned for amounts which don't fit into minor units
+	ErrRange = errors.New("amount out of range")
+	// ErrUnknownCurrency is returned when converting from or to a currency without an exchange rate
+	ErrUnknownCurrency = errors.New("no exchange rate for the currency")
+)
+
+// exponents lists the ISO 4217 currencies whose minor unit isn't a hundredth
+var exponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// Exponent returns the number of decimal places of the minor unit of currency, e.g. 2 for USD and 0 for JPY
+func Exponent(currency string) int {
+	if exp, ok := exponents[currency]; ok {
+		return exp
+	}
+	return 2
+}
+
+// Parse converts a non-negative decimal amount such as "10.5" into minor units of currency, 1050 for USD
+func Parse(amount, currency string) (int64, error) {
+	whole, fraction, _ := strings.Cut(amount, ".")
+
+	exp := Exponent(currency)
+	if trimmed := strings.TrimRight(fraction, "0"); len(trimmed) > exp {
+		return 0, ErrPrecision
+	}
+	if len(fraction) > exp {
+		fraction = fraction[:exp]
+	}
+	fraction += strings.Repeat("0", exp-len(fraction))
+
+	units, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, ErrRange
+		}
+		return 0, fmt.Errorf("invalid amount %q", amount)
+	}
+
+	return units, nil
+}
+
+// Format renders minor units of currency as a decimal amount with all its decimal places, e.g. "10.50"
+func Format(units int64, currency string) string {
+	exp := Exponent(currency)
+
+	sign := ""
+	if units < 0 {
+		sign = "-"
+	}
+	digits := strconv.FormatUint(absolute(units), 10)
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+func absolute(n int64) uint64 {
+	if n < 0 {
+		return uint64(-(n + 1)) + 1
+	}
+	return uint64(n)
+}
+
+// Multiply returns units times n, failing with ErrRange on overflow
+func Multiply(units, n int64) (int64, error) {
+	if n != 0 && (units*n)/n != units {
+		return 0, ErrRange
+	}
+	return units * n, nil
+}
+
+// Add returns the sum of a and b, failing with ErrRange on overflow
+func Add(a, b int64) (int64, error) {
+	sum := a + b
+	if (a > 0 && b > 0 && sum < 0) || (a < 0 && b < 0 && sum >= 0) {
+		return 0, ErrRange
+	}
+	return sum, nil
+}
+
+// Rates holds how much of each currency one unit of the base currency buys
+type Rates map[string]*big.Rat
+
+// ParseRates parses an exchange-rate table such as "USD=1,EUR=0.92,UZS=12650"
+func ParseRates(s string) (Rates, error) {
+	rates := make(Rates)
+
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		currency, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid exchange rate %q, expected <CURRENCY>=<rate>", item)
+		}
+
+		rate, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid exchange rate %q, rate must be a positive number", item)
+		}
+
+		rates[strings.ToUpper(strings.TrimSpace(currency))] = rate
+	}
+
+	return rates, nil
+}
+
+// Has reports whether amounts can be converted from and to currency
+func (r Rates) Has(currency string) bool {
+	_, ok := r[currency]
+	return ok
+}
+
+// Convert converts minor units of one currency into minor units of another, rounding half away from zero
+func (r Rates) Convert(units int64, from, to string) (int64, error) {
+	if from == to {
+		return units, nil
+	}
+
+	fromRate, ok := r[from]
+	if !ok {
+		return 0, ErrUnknownCurrency
+	}
+	toRate, ok := r[to]
+	if !ok {
+		return 0, ErrUnknownCurrency
+	}
+
+	// units / 10^from exponent / fromRate * toRate * 10^to exponent
+	amount := new(big.Rat).SetInt64(units)
+	amount.Mul(amount, toRate)
+	amount.Quo(amount, fromRate)
+	amount.Mul(amount, new(big.Rat).SetFrac(pow10(Exponent(to)), pow10(Exponent(from))))
+
+	rounded := roundHalfAway(amount)
+	if !rounded.IsInt64() {
+		return 0, ErrRange
+	}
+
+	return rounded.Int64(), nil
+}
+
+func pow10(exp int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+}
+
+func roundHalfAway(r *big.Rat) *big.Int {
+	num := new(big.Int).Abs(r.Num())
+	den := r.Denom()
+
+	q, m := new(big.Int).QuoRem(num, den, new(big.Int))
+	if m.Mul(m, big.NewInt(2)).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		q.Neg(q)
+	}
+
+	return q
+}
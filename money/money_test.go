@@ -0,0 +1,133 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     int64
+		err      error
+	}{
+		{amount: "10", currency: "USD", want: 1000},
+		{amount: "10.5", currency: "USD", want: 1050},
+		{amount: "10.50", currency: "USD", want: 1050},
+		{amount: "0.01", currency: "USD", want: 1},
+		{amount: "10.500", currency: "USD", want: 1050},
+		{amount: "1500", currency: "JPY", want: 1500},
+		{amount: "1500.0", currency: "JPY", want: 1500},
+		{amount: "1.234", currency: "KWD", want: 1234},
+		{amount: "10.505", currency: "USD", err: ErrPrecision},
+		{amount: "1500.5", currency: "JPY", err: ErrPrecision},
+		{amount: "1.2345", currency: "KWD", err: ErrPrecision},
+		{amount: "92233720368547758.08", currency: "USD", err: ErrRange},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.amount, tt.currency)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Parse(%q, %s) error = %v, want %v", tt.amount, tt.currency, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q, %s) = %d, want %d", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+
+	if _, err := Parse("ten", "USD"); err == nil {
+		t.Error(`Parse("ten") succeeded`)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		units    int64
+		currency string
+		want     string
+	}{
+		{units: 1050, currency: "USD", want: "10.50"},
+		{units: 5, currency: "USD", want: "0.05"},
+		{units: 0, currency: "USD", want: "0.00"},
+		{units: -5, currency: "USD", want: "-0.05"},
+		{units: 1500, currency: "JPY", want: "1500"},
+		{units: 1234, currency: "KWD", want: "1.234"},
+		{units: math.MinInt64, currency: "USD", want: "-92233720368547758.08"},
+	}
+
+	for _, tt := range tests {
+		if got := Format(tt.units, tt.currency); got != tt.want {
+			t.Errorf("Format(%d, %s) = %q, want %q", tt.units, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	rates, err := ParseRates("USD=1, EUR=0.92, JPY=150, kwd=0.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		units    int64
+		from, to string
+		want     int64
+		err      error
+	}{
+		{name: "same currency", units: 1050, from: "USD", to: "USD", want: 1050},
+		{name: "to a weaker rate", units: 10050, from: "USD", to: "EUR", want: 9246},
+		{name: "back to the base", units: 9246, from: "EUR", to: "USD", want: 10050},
+		// 0.05 USD is 0.046 EUR
+		{name: "rounds up above a half", units: 5, from: "USD", to: "EUR", want: 5},
+		// 0.01 EUR is 0.01087 USD
+		{name: "rounds down below a half", units: 1, from: "EUR", to: "USD", want: 1},
+		// 0.01 USD is 1.5 JPY
+		{name: "rounds up from a half", units: 1, from: "USD", to: "JPY", want: 2},
+		// 0.005 KWD is 1/60 USD, 1.67 cents
+		{name: "more minor units to fewer", units: 5, from: "KWD", to: "USD", want: 2},
+		// 1 USD is 150 JPY, which has no minor unit
+		{name: "to a currency without minor units", units: 100, from: "USD", to: "JPY", want: 150},
+		// 1 JPY is 0.00667 USD, 0.67 cents
+		{name: "from a currency without minor units", units: 1, from: "JPY", to: "USD", want: 1},
+		{name: "negative half rounds away from zero", units: -1, from: "USD", to: "JPY", want: -2},
+		{name: "unknown source", units: 1, from: "GBP", to: "USD", err: ErrUnknownCurrency},
+		{name: "unknown target", units: 1, from: "USD", to: "GBP", err: ErrUnknownCurrency},
+		{name: "overflow", units: math.MaxInt64, from: "USD", to: "JPY", err: ErrRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rates.Convert(tt.units, tt.from, tt.to)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("Convert(%d, %s, %s) = %d, want %d", tt.units, tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRates(t *testing.T) {
+	for _, s := range []string{"USD", "USD=0", "USD=-1", "USD=x"} {
+		if _, err := ParseRates(s); err == nil {
+			t.Errorf("ParseRates(%q) succeeded", s)
+		}
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	if _, err := Multiply(math.MaxInt64/2+1, 2); !errors.Is(err, ErrRange) {
+		t.Errorf("Multiply overflow error = %v, want ErrRange", err)
+	}
+	if _, err := Add(math.MaxInt64, 1); !errors.Is(err, ErrRange) {
+		t.Errorf("Add overflow error = %v, want ErrRange", err)
+	}
+	if got, err := Multiply(250, 3); err != nil || got != 750 {
+		t.Errorf("Multiply(250, 3) = %d, %v", got, err)
+	}
+}
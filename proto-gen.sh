@@ -12,4 +12,8 @@ protoc --go_out=./proto-gen \
 
 protoc --go_out=./proto-gen \
 --go-grpc_out=./proto-gen \
-./protos/auth.proto
\ No newline at end of file
+./protos/auth.proto
+
+protoc --go_out=./proto-gen \
+--go-grpc_out=./proto-gen \
+./protos/money.proto
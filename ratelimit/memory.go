@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	key    string
+	tokens float64
+	last   time.Time
+	rate   Rate
+}
+
+// MemoryStore keeps up to size buckets in process memory, suitable for a single gateway instance.
+// The least recently used bucket is evicted first, so a flood of new identities can't exhaust memory.
+type MemoryStore struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List
+	buckets map[string]*list.Element
+}
+
+// NewMemoryStore ...
+func NewMemoryStore(size int) *MemoryStore {
+	return &MemoryStore{
+		size:    size,
+		order:   list.New(),
+		buckets: make(map[string]*list.Element),
+	}
+}
+
+// Take ...
+func (s *MemoryStore) Take(ctx context.Context, key string, rate Rate) (Result, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var b *bucket
+	if el, ok := s.buckets[key]; ok {
+		b = el.Value.(*bucket)
+		s.order.MoveToFront(el)
+	} else {
+		b = &bucket{key: key, tokens: float64(rate.Limit), last: now, rate: rate}
+		s.buckets[key] = s.order.PushFront(b)
+
+		for s.order.Len() > s.size {
+			el := s.order.Back()
+			s.order.Remove(el)
+			delete(s.buckets, el.Value.(*bucket).key)
+		}
+	}
+
+	b.refill(now)
+
+	result := Result{Limit: rate.Limit}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - b.tokens) * float64(rate.refillInterval()))
+	}
+
+	result.Remaining = int(math.Floor(b.tokens))
+	result.ResetAfter = time.Duration((float64(rate.Limit) - b.tokens) * float64(rate.refillInterval()))
+
+	return result, nil
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last)
+	b.last = now
+
+	b.tokens += float64(elapsed) / float64(b.rate.refillInterval())
+	if b.tokens > float64(b.rate.Limit) {
+		b.tokens = float64(b.rate.Limit)
+	}
+}
@@ -0,0 +1,182 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+
+	"github.com/uacademy/e_commerce/api_gateway/config"
+)
+
+// Supported stores
+const (
+	StoreMemory = "memory"
+	StoreRedis  = "redis"
+)
+
+// Rate allows Limit requests per Period, refilled continuously (token bucket)
+type Rate struct {
+	Limit  int
+	Period time.Duration
+}
+
+// Result of taking a token from a bucket
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next token, zero when allowed
+}
+
+// Store keeps token buckets
+type Store interface {
+	Take(ctx context.Context, key string, rate Rate) (Result, error)
+}
+
+// ParseRate parses rates such as "10/s", "100/m", "1000/h" or "5/10s"
+func ParseRate(s string) (Rate, error) {
+	parts := strings.SplitN(strings.TrimSpace(s), "/", 2)
+	if len(parts) != 2 {
+		return Rate{}, fmt.Errorf("invalid rate %q, expected <limit>/<period>", s)
+	}
+
+	limit, err := strconv.Atoi(parts[0])
+	if err != nil || limit <= 0 {
+		return Rate{}, fmt.Errorf("invalid rate %q, limit must be a positive integer", s)
+	}
+
+	var period time.Duration
+	switch parts[1] {
+	case "s":
+		period = time.Second
+	case "m":
+		period = time.Minute
+	case "h":
+		period = time.Hour
+	default:
+		period, err = time.ParseDuration(parts[1])
+		if err != nil || period <= 0 {
+			return Rate{}, fmt.Errorf("invalid rate %q, unknown period", s)
+		}
+	}
+
+	return Rate{Limit: limit, Period: period}, nil
+}
+
+// ParseRouteRates parses per-route overrides such as
+// "POST /v1/login=5/m,POST /v1/order=10/m" into a map keyed by "METHOD route"
+func ParseRouteRates(s string) (map[string]Rate, error) {
+	rates := make(map[string]Rate)
+
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		i := strings.LastIndex(item, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid route rate %q, expected <METHOD> <route>=<rate>", item)
+		}
+
+		rate, err := ParseRate(item[i+1:])
+		if err != nil {
+			return nil, err
+		}
+
+		rates[strings.Join(strings.Fields(item[:i]), " ")] = rate
+	}
+
+	return rates, nil
+}
+
+// New builds the limiter described in config
+func New(cfg config.Config, pool *redis.Pool) (*Limiter, error) {
+	defaultRate, err := ParseRate(cfg.RateLimitDefault)
+	if err != nil {
+		return nil, err
+	}
+
+	routeRates, err := ParseRouteRates(cfg.RateLimitRoutes)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := newStore(cfg, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLimiter(store, defaultRate, routeRates), nil
+}
+
+// NewPreAuth builds the limiter of RATE_LIMIT_PRE_AUTH, which applies to every route per client IP.
+// Its buckets are apart from those of New even in a shared store.
+func NewPreAuth(cfg config.Config, pool *redis.Pool) (*Limiter, error) {
+	rate, err := ParseRate(cfg.RateLimitPreAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := newStore(cfg, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	l := NewLimiter(store, rate, nil)
+	l.prefix = "pre-auth:"
+	return l, nil
+}
+
+func newStore(cfg config.Config, pool *redis.Pool) (Store, error) {
+	switch cfg.RateLimitStore {
+	case StoreMemory:
+		return NewMemoryStore(cfg.RateLimitMemorySize), nil
+	case StoreRedis:
+		return NewRedisStore(pool), nil
+	}
+
+	return nil, fmt.Errorf("unknown rate limit store %q", cfg.RateLimitStore)
+}
+
+// Limiter picks the rate for a route and takes tokens from the store
+type Limiter struct {
+	store       Store
+	defaultRate Rate
+	routeRates  map[string]Rate
+	// prefix keeps the buckets of limiters sharing a store apart
+	prefix string
+}
+
+// NewLimiter ...
+func NewLimiter(store Store, defaultRate Rate, routeRates map[string]Rate) *Limiter {
+	return &Limiter{
+		store:       store,
+		defaultRate: defaultRate,
+		routeRates:  routeRates,
+	}
+}
+
+// Take takes a token for identity on method + route.
+// Routes with an override get their own bucket, all others share the default one.
+func (l *Limiter) Take(ctx context.Context, method, route, identity string) (Result, error) {
+	scope := method + " " + route
+
+	rate, ok := l.routeRates[scope]
+	if !ok {
+		rate = l.defaultRate
+		scope = "default"
+	}
+
+	return l.store.Take(ctx, l.prefix+scope+"|"+identity, rate)
+}
+
+// refillInterval is the time it takes to refill a single token
+func (r Rate) refillInterval() time.Duration {
+	return r.Period / time.Duration(r.Limit)
+}
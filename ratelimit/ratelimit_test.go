@@ -0,0 +1,173 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Rate
+		wantErr bool
+	}{
+		{in: "10/s", want: Rate{Limit: 10, Period: time.Second}},
+		{in: "100/m", want: Rate{Limit: 100, Period: time.Minute}},
+		{in: " 1000/h ", want: Rate{Limit: 1000, Period: time.Hour}},
+		{in: "5/10s", want: Rate{Limit: 5, Period: 10 * time.Second}},
+		{in: "10", wantErr: true},
+		{in: "0/m", wantErr: true},
+		{in: "-1/m", wantErr: true},
+		{in: "x/m", wantErr: true},
+		{in: "10/fortnight", wantErr: true},
+		{in: "10/-1s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRate(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRate(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRate(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseRouteRates(t *testing.T) {
+	rates, err := ParseRouteRates("POST  /v1/login=5/m, GET /v1/product=10/s,")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]Rate{
+		"POST /v1/login":  {Limit: 5, Period: time.Minute},
+		"GET /v1/product": {Limit: 10, Period: time.Second},
+	}
+	if len(rates) != len(want) {
+		t.Fatalf("rates = %+v, want %+v", rates, want)
+	}
+	for scope, rate := range want {
+		if rates[scope] != rate {
+			t.Errorf("rates[%q] = %+v, want %+v", scope, rates[scope], rate)
+		}
+	}
+
+	if _, err := ParseRouteRates("POST /v1/login"); err == nil {
+		t.Error("a route without a rate was accepted")
+	}
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	// an hour per token, so that nothing is refilled while the test runs
+	rate := Rate{Limit: 3, Period: 3 * time.Hour}
+	store := NewMemoryStore(10)
+
+	tests := []struct {
+		allowed   bool
+		remaining int
+	}{
+		{allowed: true, remaining: 2},
+		{allowed: true, remaining: 1},
+		{allowed: true, remaining: 0},
+		{allowed: false, remaining: 0},
+	}
+
+	for i, tt := range tests {
+		result, err := store.Take(context.Background(), "ip:1", rate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Allowed != tt.allowed || result.Remaining != tt.remaining || result.Limit != 3 {
+			t.Errorf("take %d = %+v, want allowed %v and %d remaining", i, result, tt.allowed, tt.remaining)
+		}
+		if tt.allowed && result.RetryAfter != 0 {
+			t.Errorf("take %d retry after = %v, want none when allowed", i, result.RetryAfter)
+		}
+		if !tt.allowed && (result.RetryAfter <= 0 || result.RetryAfter > time.Hour) {
+			t.Errorf("take %d retry after = %v, want up to the refill of one token", i, result.RetryAfter)
+		}
+		if result.ResetAfter > 3*time.Hour {
+			t.Errorf("take %d reset after = %v, want at most the period", i, result.ResetAfter)
+		}
+	}
+
+	result, _ := store.Take(context.Background(), "ip:2", rate)
+	if !result.Allowed || result.Remaining != 2 {
+		t.Errorf("other key = %+v, want a bucket of its own", result)
+	}
+}
+
+func TestMemoryStoreRefill(t *testing.T) {
+	rate := Rate{Limit: 1, Period: 20 * time.Millisecond}
+	store := NewMemoryStore(10)
+
+	if result, _ := store.Take(context.Background(), "ip:1", rate); !result.Allowed {
+		t.Fatal("first take was rejected")
+	}
+	if result, _ := store.Take(context.Background(), "ip:1", rate); result.Allowed {
+		t.Fatal("second take was allowed before the refill")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if result, _ := store.Take(context.Background(), "ip:1", rate); !result.Allowed {
+		t.Error("take after the refill was rejected")
+	}
+}
+
+func TestMemoryStoreEviction(t *testing.T) {
+	rate := Rate{Limit: 1, Period: time.Hour}
+	store := NewMemoryStore(2)
+
+	for _, key := range []string{"a", "b", "a", "c"} {
+		store.Take(context.Background(), key, rate)
+	}
+
+	if result, _ := store.Take(context.Background(), "a", rate); result.Allowed {
+		t.Error("a was evicted although it was used recently")
+	}
+	// b was the least recently used when c came in, its bucket starts over
+	if result, _ := store.Take(context.Background(), "b", rate); !result.Allowed {
+		t.Error("b was not evicted")
+	}
+}
+
+func TestLimiterScopes(t *testing.T) {
+	store := NewMemoryStore(10)
+	limiter := NewLimiter(store, Rate{Limit: 1, Period: time.Hour}, map[string]Rate{
+		"POST /v1/login": {Limit: 2, Period: time.Hour},
+	})
+
+	tests := []struct {
+		method, route string
+		allowed       bool
+		limit         int
+	}{
+		{method: "GET", route: "/v1/product", allowed: true, limit: 1},
+		// routes without an override share the default bucket
+		{method: "GET", route: "/v1/category", allowed: false, limit: 1},
+		{method: "POST", route: "/v1/login", allowed: true, limit: 2},
+		{method: "POST", route: "/v1/login", allowed: true, limit: 2},
+		{method: "POST", route: "/v1/login", allowed: false, limit: 2},
+	}
+
+	for _, tt := range tests {
+		result, err := limiter.Take(context.Background(), tt.method, tt.route, "ip:1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Allowed != tt.allowed || result.Limit != tt.limit {
+			t.Errorf("%s %s = %+v, want allowed %v with limit %d", tt.method, tt.route, result, tt.allowed, tt.limit)
+		}
+	}
+
+	// a limiter with a prefix doesn't share buckets even in the same store
+	preAuth := NewLimiter(store, Rate{Limit: 1, Period: time.Hour}, nil)
+	preAuth.prefix = "pre-auth:"
+	if result, _ := preAuth.Take(context.Background(), "GET", "/v1/product", "ip:1"); !result.Allowed {
+		t.Error("pre-auth limiter shares the default bucket")
+	}
+}
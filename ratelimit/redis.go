@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// takeScript refills and takes a token atomically.
+// KEYS[1] bucket key; ARGV: limit, refill interval (µs), now (µs), ttl (ms).
+// Returns {allowed, tokens * 1000}.
+var takeScript = redis.NewScript(1, `
+local limit = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "last")
+local tokens = tonumber(state[1])
+local last = tonumber(state[2])
+
+if tokens == nil then
+	tokens = limit
+	last = now
+end
+
+if now > last then
+	tokens = math.min(limit, tokens + (now - last) / interval)
+	last = now
+end
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "last", tostring(last))
+redis.call("PEXPIRE", KEYS[1], ttl)
+
+return {allowed, math.floor(tokens * 1000)}
+`)
+
+// RedisStore keeps buckets in a Redis-protocol server so that all gateway instances share them
+type RedisStore struct {
+	pool   *redis.Pool
+	prefix string
+}
+
+// NewRedisStore ...
+func NewRedisStore(pool *redis.Pool) *RedisStore {
+	return &RedisStore{
+		pool:   pool,
+		prefix: "ratelimit:",
+	}
+}
+
+// Take ...
+func (s *RedisStore) Take(ctx context.Context, key string, rate Rate) (Result, error) {
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	defer conn.Close()
+
+	interval := rate.refillInterval()
+
+	values, err := redis.Int64s(takeScript.Do(conn,
+		s.prefix+key,
+		rate.Limit,
+		interval.Microseconds(),
+		time.Now().UnixMicro(),
+		rate.Period.Milliseconds(),
+	))
+	if err != nil {
+		return Result{}, err
+	}
+
+	tokens := float64(values[1]) / 1000
+
+	result := Result{
+		Allowed:    values[0] == 1,
+		Limit:      rate.Limit,
+		Remaining:  int(tokens),
+		ResetAfter: time.Duration((float64(rate.Limit) - tokens) * float64(interval)),
+	}
+	if !result.Allowed {
+		result.RetryAfter = time.Duration((1 - tokens) * float64(interval))
+	}
+
+	return result, nil
+}
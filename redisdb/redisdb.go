@@ -0,0 +1,33 @@
+package redisdb
+
+import (
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+
+	"github.com/uacademy/e_commerce/api_gateway/config"
+)
+
+// NewPool returns a connection pool for any server speaking the Redis protocol
+func NewPool(cfg config.Config) *redis.Pool {
+	return &redis.Pool{
+		MaxIdle:     cfg.RedisMaxIdle,
+		IdleTimeout: 5 * time.Minute,
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", cfg.RedisAddr,
+				redis.DialPassword(cfg.RedisPassword),
+				redis.DialDatabase(cfg.RedisDB),
+				redis.DialConnectTimeout(time.Second),
+				redis.DialReadTimeout(time.Second),
+				redis.DialWriteTimeout(time.Second),
+			)
+		},
+		TestOnBorrow: func(conn redis.Conn, lastUsed time.Time) error {
+			if time.Since(lastUsed) < time.Minute {
+				return nil
+			}
+			_, err := conn.Do("PING")
+			return err
+		},
+	}
+}
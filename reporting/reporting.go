@@ -0,0 +1,30 @@
+package reporting
+
+import (
+	"context"
+	"time"
+)
+
+// Event describes an unexpected failure worth forwarding to an error tracker
+type Event struct {
+	Message   string
+	Stack     string
+	RequestID string
+	Method    string
+	Route     string
+	Path      string
+	Timestamp time.Time
+}
+
+// Reporter forwards events to an error tracking backend
+type Reporter interface {
+	Report(ctx context.Context, event Event) error
+}
+
+// Nop discards every event
+type Nop struct{}
+
+// Report ...
+func (Nop) Report(context.Context, Event) error {
+	return nil
+}
@@ -0,0 +1,130 @@
+package reporting
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+const sentryClient = "api_gateway/1.0"
+
+// SentryReporter posts events to a Sentry-compatible store endpoint
+type SentryReporter struct {
+	endpoint    string
+	auth        string
+	environment string
+	release     string
+	client      *http.Client
+}
+
+// NewSentryReporter parses a DSN of the form scheme://key@host[:port][/path]/project.
+// Any server implementing the Sentry store API can receive the events, including a local stub.
+func NewSentryReporter(dsn, environment, release string) (*SentryReporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid error reporting dsn: %w", err)
+	}
+
+	if u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("invalid error reporting dsn: missing public key")
+	}
+
+	projectPath, projectID := path.Split(strings.TrimSuffix(u.Path, "/"))
+	if projectID == "" {
+		return nil, fmt.Errorf("invalid error reporting dsn: missing project id")
+	}
+
+	endpoint := url.URL{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+		Path:   path.Join(projectPath, "api", projectID, "store") + "/",
+	}
+
+	auth := fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s", sentryClient, u.User.Username())
+	if secret, ok := u.User.Password(); ok {
+		auth += ", sentry_secret=" + secret
+	}
+
+	return &SentryReporter{
+		endpoint:    endpoint.String(),
+		auth:        auth,
+		environment: environment,
+		release:     release,
+		client:      &http.Client{Timeout: 5 * time.Second},
+	}, nil
+}
+
+type sentryEvent struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   string                 `json:"timestamp"`
+	Level       string                 `json:"level"`
+	Platform    string                 `json:"platform"`
+	Logger      string                 `json:"logger"`
+	Message     string                 `json:"message"`
+	Environment string                 `json:"environment,omitempty"`
+	Release     string                 `json:"release,omitempty"`
+	Tags        map[string]string      `json:"tags,omitempty"`
+	Request     map[string]string      `json:"request,omitempty"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+}
+
+// Report ...
+func (r *SentryReporter) Report(ctx context.Context, event Event) error {
+	body, err := json.Marshal(sentryEvent{
+		EventID:     newEventID(),
+		Timestamp:   event.Timestamp.UTC().Format(time.RFC3339),
+		Level:       "error",
+		Platform:    "go",
+		Logger:      "api_gateway",
+		Message:     event.Message,
+		Environment: r.environment,
+		Release:     r.release,
+		Tags: map[string]string{
+			"request_id": event.RequestID,
+			"route":      event.Route,
+		},
+		Request: map[string]string{
+			"method": event.Method,
+			"url":    event.Path,
+		},
+		Extra: map[string]interface{}{
+			"stack": event.Stack,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", r.auth)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("error reporting endpoint responded with %s", resp.Status)
+	}
+
+	return nil
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,102 @@
+package reporting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSentryReporter(t *testing.T) {
+	var (
+		path, auth string
+		received   sentryEvent
+	)
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		auth = r.Header.Get("X-Sentry-Auth")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer stub.Close()
+
+	dsn := strings.Replace(stub.URL, "://", "://public:secret@", 1) + "/sentry/42"
+	reporter, err := NewSentryReporter(dsn, "staging", "1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = reporter.Report(context.Background(), Event{
+		Message:   "boom",
+		Stack:     "goroutine 1 [running]:",
+		RequestID: "req-1",
+		Method:    http.MethodGet,
+		Route:     "/v1/product/:id",
+		Path:      "/v1/product/7",
+		Timestamp: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if path != "/sentry/api/42/store/" {
+		t.Errorf("path = %q, want the store endpoint of project 42", path)
+	}
+	for _, want := range []string{"sentry_version=7", "sentry_key=public", "sentry_secret=secret"} {
+		if !strings.Contains(auth, want) {
+			t.Errorf("X-Sentry-Auth = %q, missing %s", auth, want)
+		}
+	}
+
+	if len(received.EventID) != 32 {
+		t.Errorf("event_id = %q, want 32 hex digits", received.EventID)
+	}
+	if received.Message != "boom" || received.Level != "error" || received.Timestamp != "2022-01-02T03:04:05Z" {
+		t.Errorf("event = %+v", received)
+	}
+	if received.Environment != "staging" || received.Release != "1.2.3" {
+		t.Errorf("environment, release = %q, %q", received.Environment, received.Release)
+	}
+	if received.Tags["request_id"] != "req-1" || received.Tags["route"] != "/v1/product/:id" {
+		t.Errorf("tags = %v", received.Tags)
+	}
+	if received.Request["method"] != http.MethodGet || received.Request["url"] != "/v1/product/7" {
+		t.Errorf("request = %v", received.Request)
+	}
+	if received.Extra["stack"] != "goroutine 1 [running]:" {
+		t.Errorf("extra = %v", received.Extra)
+	}
+}
+
+func TestSentryReporterRejected(t *testing.T) {
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer stub.Close()
+
+	reporter, err := NewSentryReporter(strings.Replace(stub.URL, "://", "://public@", 1)+"/1", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reporter.Report(context.Background(), Event{Message: "boom"}); err == nil {
+		t.Error("expected an error when the endpoint rejects the event")
+	}
+}
+
+func TestNewSentryReporterInvalidDSN(t *testing.T) {
+	for _, dsn := range []string{
+		"http://localhost:9000/1",
+		"http://public@localhost:9000/",
+		"://public@localhost/1",
+	} {
+		if _, err := NewSentryReporter(dsn, "", ""); err == nil {
+			t.Errorf("NewSentryReporter(%q) succeeded", dsn)
+		}
+	}
+}
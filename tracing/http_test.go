@@ -0,0 +1,155 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/uacademy/e_commerce/api_gateway/config"
+)
+
+const (
+	remoteTraceId = "4bf92f3577b34da6a3ce929d0e0e4736"
+	remoteSpanId  = "00f067aa0ba902b7"
+)
+
+// newTestProvider installs a provider recording every ended span in memory
+func newTestProvider(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := NewTracerProvider(config.Config{App: "test", TracingSampleRatio: 1}, sdktrace.NewSimpleSpanProcessor(exporter))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(Propagator())
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		tp.Shutdown(context.Background())
+	})
+
+	return exporter
+}
+
+func TestMiddleware(t *testing.T) {
+	exporter := newTestProvider(t)
+	gin.SetMode(gin.TestMode)
+
+	conn, err := grpc.Dial("passthrough:///order:9002", grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var outgoing metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		outgoing, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+
+	r := gin.New()
+	r.Use(Middleware())
+	r.GET("/v1/order/:id", func(c *gin.Context) {
+		err := UnaryClientInterceptor()(c.Request.Context(), "/order_service.OrderService/GetOrderById", nil, nil, conn, invoker)
+		if err != nil {
+			t.Error(err)
+		}
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/order/42", nil)
+	req.Header.Set("traceparent", "00-"+remoteTraceId+"-"+remoteSpanId+"-01")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	// the client span ends first
+	client, server := spans[0], spans[1]
+
+	if server.Name != "GET /v1/order/:id" {
+		t.Errorf("server span name = %q, want the route template", server.Name)
+	}
+	if server.SpanKind != trace.SpanKindServer {
+		t.Errorf("server span kind = %v", server.SpanKind)
+	}
+	if got := server.SpanContext.TraceID().String(); got != remoteTraceId {
+		t.Errorf("server span trace id = %s, want the one from traceparent %s", got, remoteTraceId)
+	}
+	if got := server.Parent.SpanID().String(); got != remoteSpanId || !server.Parent.IsRemote() {
+		t.Errorf("server span parent = %s, want remote %s", got, remoteSpanId)
+	}
+	assertAttribute(t, server.Attributes, "http.route", attribute.StringValue("/v1/order/:id"))
+	assertAttribute(t, server.Attributes, "http.method", attribute.StringValue(http.MethodGet))
+	assertAttribute(t, server.Attributes, "http.status_code", attribute.IntValue(http.StatusOK))
+
+	if client.Name != "order_service.OrderService/GetOrderById" {
+		t.Errorf("client span name = %q", client.Name)
+	}
+	if client.SpanKind != trace.SpanKindClient {
+		t.Errorf("client span kind = %v", client.SpanKind)
+	}
+	if client.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Errorf("client span parent = %s, want the server span %s", client.Parent.SpanID(), server.SpanContext.SpanID())
+	}
+	assertAttribute(t, client.Attributes, "rpc.system", attribute.StringValue("grpc"))
+	assertAttribute(t, client.Attributes, "rpc.service", attribute.StringValue("order_service.OrderService"))
+	assertAttribute(t, client.Attributes, "rpc.method", attribute.StringValue("GetOrderById"))
+	assertAttribute(t, client.Attributes, "rpc.grpc.status_code", attribute.Int64Value(0))
+
+	want := "00-" + remoteTraceId + "-" + client.SpanContext.SpanID().String() + "-01"
+	if got := outgoing.Get("traceparent"); len(got) != 1 || got[0] != want {
+		t.Errorf("outgoing traceparent = %v, want %s", got, want)
+	}
+}
+
+func TestMiddlewareStartsTrace(t *testing.T) {
+	exporter := newTestProvider(t)
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(Middleware())
+	r.GET("/v1/product/:id", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/product/1", nil))
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if spans[0].Parent.IsValid() {
+		t.Errorf("span without traceparent has parent %s", spans[0].Parent.SpanID())
+	}
+	if spans[0].Status.Code.String() != "Error" {
+		t.Errorf("span status = %v, want Error for a 500", spans[0].Status.Code)
+	}
+}
+
+func assertAttribute(t *testing.T, attrs []attribute.KeyValue, key string, want attribute.Value) {
+	t.Helper()
+
+	for _, kv := range attrs {
+		if string(kv.Key) == key {
+			if kv.Value != want {
+				t.Errorf("%s = %v, want %v", key, kv.Value.Emit(), want.Emit())
+			}
+			return
+		}
+	}
+	t.Errorf("%s is missing", key)
+}
@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/uacademy/e_commerce/api_gateway/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/uacademy/e_commerce/api_gateway"
+
+// Supported exporters
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Init configures the global tracer provider and W3C propagator from config.
+// The returned function flushes and stops the provider and must be called on shutdown.
+func Init(cfg config.Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(Propagator())
+
+	exporter, err := newExporter(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	tp := NewTracerProvider(cfg, sdktrace.NewBatchSpanProcessor(exporter))
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+// NewTracerProvider builds a provider handing spans to processor. Init batches them for its exporter;
+// tests pass sdktrace.NewSimpleSpanProcessor(tracetest.NewInMemoryExporter()) to see spans as soon as they end.
+func NewTracerProvider(cfg config.Config, processor sdktrace.SpanProcessor) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.TracingSampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(cfg.App),
+			semconv.ServiceVersionKey.String(cfg.AppVersion),
+			semconv.DeploymentEnvironmentKey.String(cfg.Environment),
+		)),
+	)
+}
+
+// Propagator reads and writes W3C traceparent and baggage headers
+func Propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	)
+}
+
+func newExporter(cfg config.Config) (sdktrace.SpanExporter, error) {
+	switch cfg.TracingExporter {
+	case ExporterNone, "":
+		return nil, nil
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.TracingOTLPEndpoint)}
+		if cfg.TracingOTLPInsecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(context.Background(), opts...)
+	}
+
+	return nil, fmt.Errorf("unknown tracing exporter %q", cfg.TracingExporter)
+}
+
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}